@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
 )
 
 // RCSConfigSpec defines the desired state of RCSConfig
@@ -38,6 +40,308 @@ type RCSConfigSpec struct {
 	// If the Capp hostname matches a pattern, it is blocked from being created.
 	// +kubebuilder:default:={}
 	InvalidHostnamePatterns []string `json:"invalidHostnamePatterns"`
+
+	// AuthorizeSites enables the authorization of users against the virtual "sites" resource of the rcs.dana.io
+	// API group, named after the site of a Capp. Users must be allowed to "deploy" to the site of the Capps they create
+	// or whose site they set, and to "migrate" to the new site of Capps whose site they change after placement.
+	// +optional
+	AuthorizeSites bool `json:"authorizeSites,omitempty"`
+
+	// HostnameDNSCheck is an optional configuration of a DNS lookup of the hostname of a Capp, which denies hostnames
+	// that already resolve. Hostnames are always checked for uniqueness against the other Capps on the hub.
+	// +optional
+	HostnameDNSCheck *HostnameDNSCheck `json:"hostnameDNSCheck,omitempty"`
+
+	// DomainDelegations is an optional slice of the domains delegated to namespaces. When it is set, the hostname
+	// of a Capp must be within one of the domains delegated to its namespace.
+	// +optional
+	DomainDelegations []DomainDelegation `json:"domainDelegations,omitempty"`
+
+	// UpdateStrategies is an optional slice of update strategies used by the work agent
+	// on the managed cluster for each kind of resource in the ManifestWork.
+	// Resources which do not match any strategy are updated using the default Update strategy.
+	// +optional
+	UpdateStrategies []ResourceUpdateStrategy `json:"updateStrategies,omitempty"`
+
+	// ManifestWorkSizeLimit is an optional maximum size of the serialized manifests of a single ManifestWork.
+	// When the manifests of a Capp exceed it, they are distributed across several ManifestWorks.
+	// Defaults to 500Ki.
+	// +optional
+	ManifestWorkSizeLimit *resource.Quantity `json:"manifestWorkSizeLimit,omitempty"`
+
+	// OrphanCollection is an optional configuration of the collection of orphaned ManifestWorks,
+	// whose Capp no longer exists or is placed on a different managed cluster.
+	// +optional
+	OrphanCollection *OrphanCollection `json:"orphanCollection,omitempty"`
+
+	// DeletionTimeout is an optional duration to wait for the ManifestWorks of a deleted Capp to be removed
+	// from the managed clusters, after which the Capp reports that its deletion timed out. Defaults to 10m.
+	// +optional
+	DeletionTimeout *metav1.Duration `json:"deletionTimeout,omitempty"`
+
+	// BreakGlassGroups is an optional slice of groups whose members are allowed to create, update and delete
+	// the ManifestWorks managed by the operator, which are otherwise protected from changes by anyone but the operator.
+	// +optional
+	BreakGlassGroups []string `json:"breakGlassGroups,omitempty"`
+
+	// RevisionHistoryLimit is an optional number of revisions of the manifests shipped for each Capp to keep,
+	// so that the Capp can be rolled back to one of them. Setting it to 0 disables the history. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// MaintenanceWindows is an optional slice of windows outside of which updates of the ManifestWorks
+	// of Capps in the namespaces they apply to are deferred. Creations and deletions are not deferred.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// DeployFreezes is an optional slice of freezes during which updates of the ManifestWorks
+	// of Capps in the namespaces they apply to are deferred. Creations and deletions are not deferred.
+	// +optional
+	DeployFreezes []DeployFreeze `json:"deployFreezes,omitempty"`
+
+	// NamespacePropagation is an optional configuration of the metadata and objects of the namespace of a Capp
+	// which are propagated to the namespace on the managed cluster. By default, only the namespace itself is created.
+	// +optional
+	NamespacePropagation *NamespacePropagation `json:"namespacePropagation,omitempty"`
+
+	// ResourceCeilings is an optional configuration of the maximal resources a Capp may request or be limited to.
+	// +optional
+	ResourceCeilings *ResourceCeilings `json:"resourceCeilings,omitempty"`
+
+	// ResourceProfiles is an optional slice of profiles of default resources and resource ceilings.
+	// The first profile matching a Capp overrides DefaultResources and ResourceCeilings for the resources it sets.
+	// +optional
+	ResourceProfiles []ResourceProfile `json:"resourceProfiles,omitempty"`
+
+	// ImagePolicy is an optional configuration of the registries and repositories the images of Capps
+	// may be pulled from, and of whether they may use mutable tags. By default, all images are allowed.
+	// +optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
+
+	// ImageDigestResolution is an optional configuration of the resolution of the tags of the images of Capps
+	// to digests by the mutating webhook, so that all managed clusters run the same images. Disabled by default.
+	// +optional
+	ImageDigestResolution *ImageDigestResolution `json:"imageDigestResolution,omitempty"`
+
+	// PolicySeverities is an optional map of the names of the policy rules of the validating webhook to their severity,
+	// overriding their default severity.
+	// +optional
+	PolicySeverities map[string]PolicySeverity `json:"policySeverities,omitempty"`
+}
+
+// PolicySeverity defines what the validating webhook does when a Capp violates a policy rule.
+// +kubebuilder:validation:Enum=Enforce;Warn;Disabled
+type PolicySeverity string
+
+const (
+	// PolicySeverityEnforce denies Capps violating the rule.
+	PolicySeverityEnforce PolicySeverity = "Enforce"
+	// PolicySeverityWarn admits Capps violating the rule with an admission warning.
+	PolicySeverityWarn PolicySeverity = "Warn"
+	// PolicySeverityDisabled does not evaluate the rule.
+	PolicySeverityDisabled PolicySeverity = "Disabled"
+)
+
+// ResourceCeilings defines the maximal resources a Capp may request or be limited to.
+type ResourceCeilings struct {
+	// Container is the maximal quantity of each resource a single container may request or be limited to.
+	// +optional
+	Container corev1.ResourceList `json:"container,omitempty"`
+
+	// Capp is the maximal total quantity of each resource the containers of a Capp may request or be limited to.
+	// +optional
+	Capp corev1.ResourceList `json:"capp,omitempty"`
+}
+
+// ResourceProfile defines the default resources and resource ceilings of the Capps it matches.
+type ResourceProfile struct {
+	// Name is the name of the profile.
+	Name string `json:"name"`
+
+	// Namespaces is an optional slice of the namespaces of the Capps the profile matches.
+	// The profile matches Capps in all namespaces if it is empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector is an optional selector of the labels of the Capps the profile matches.
+	// The profile matches Capps regardless of their labels if it is not set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// DefaultResources is the default resources assigned to the containers of the Capps the profile matches.
+	// +optional
+	DefaultResources corev1.ResourceRequirements `json:"defaultResources,omitempty"`
+
+	// Ceilings is the maximal resources of the Capps the profile matches.
+	// +optional
+	Ceilings *ResourceCeilings `json:"ceilings,omitempty"`
+}
+
+// DomainDelegation defines domains which the Capps of the namespaces it applies to may use as hostnames.
+type DomainDelegation struct {
+	// Domains is a slice of the delegated domains. A domain allows itself and all its subdomains,
+	// while a domain starting with "*." only allows its subdomains.
+	Domains []string `json:"domains"`
+
+	// Namespaces is an optional slice of the namespaces the domains are delegated to.
+	// A "*" delegates the domains to all namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector is an optional selector of the labels of the namespaces the domains are delegated to.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// ImagePolicy defines the images the containers and init containers of Capps may use.
+type ImagePolicy struct {
+	// AllowedRegistries is an optional slice of the registries, such as "registry.example.com", or repositories,
+	// such as "registry.example.com/team-a", images may be pulled from. All registries are allowed if it is empty.
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// DenyMutableTags denies images using the "latest" tag, or no tag at all.
+	// +optional
+	DenyMutableTags bool `json:"denyMutableTags,omitempty"`
+
+	// Exceptions is an optional slice of exceptions to the policy for Capps in specific namespaces.
+	// +optional
+	Exceptions []ImagePolicyException `json:"exceptions,omitempty"`
+}
+
+// ImagePolicyException defines an exception to the image policy for the Capps of the namespaces it applies to.
+type ImagePolicyException struct {
+	// Namespaces is a slice of the namespaces the exception applies to.
+	Namespaces []string `json:"namespaces"`
+
+	// AllowedRegistries is an optional slice of registries or repositories images may be pulled from,
+	// in addition to those allowed by the policy.
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// AllowMutableTags allows images using mutable tags, even if the policy denies them.
+	// +optional
+	AllowMutableTags bool `json:"allowMutableTags,omitempty"`
+}
+
+// ImageDigestResolution defines the resolution of the tags of the images of Capps to digests.
+type ImageDigestResolution struct {
+	// Timeout is the timeout of resolving the digests of the images of a Capp. Images whose digest
+	// could not be resolved in time are left unchanged. Defaults to 5s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// PullSecrets is an optional slice of the names of image pull Secrets in the namespace of the RCS Config,
+	// whose credentials are used in addition to those of the imagePullSecrets of the Capp.
+	// +optional
+	PullSecrets []string `json:"pullSecrets,omitempty"`
+}
+
+// HostnameDNSCheck defines the DNS lookup of the hostname of a Capp.
+type HostnameDNSCheck struct {
+	// Timeout is the timeout of the DNS lookup. Defaults to 2s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// NamespacePropagation defines what is propagated from the namespace of a Capp on the hub to the managed cluster.
+type NamespacePropagation struct {
+	// Labels is an optional slice of the keys of the namespace labels to propagate.
+	// A key ending with "*" matches all the keys starting with its prefix, e.g. "pod-security.kubernetes.io/*".
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations is an optional slice of the keys of the namespace annotations to propagate.
+	// A key ending with "*" matches all the keys starting with its prefix.
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
+
+	// ResourceQuotas indicates whether the ResourceQuotas of the namespace are propagated.
+	// +optional
+	ResourceQuotas bool `json:"resourceQuotas,omitempty"`
+
+	// LimitRanges indicates whether the LimitRanges of the namespace are propagated.
+	// +optional
+	LimitRanges bool `json:"limitRanges,omitempty"`
+}
+
+// TimeWindow defines a recurring window of time.
+type TimeWindow struct {
+	// Schedule is a standard 5-field cron expression of the times at which the window starts.
+	Schedule string `json:"schedule"`
+
+	// Duration is the duration of the window from each of its starts.
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA name of the time zone of the schedule. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring window during which updates are allowed.
+type MaintenanceWindow struct {
+	TimeWindow `json:",inline"`
+
+	// Namespaces is an optional slice of the namespaces the window applies to.
+	// The window applies to all namespaces if it is empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// DeployFreeze defines a recurring window during which updates are not allowed.
+type DeployFreeze struct {
+	// Name is the name of the freeze, reported in the Capps whose updates it defers.
+	Name string `json:"name"`
+
+	TimeWindow `json:",inline"`
+
+	// Namespaces is an optional slice of the namespaces the freeze applies to.
+	// The freeze applies to all namespaces if it is empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// OrphanCollectionMode defines what is done with orphaned ManifestWorks.
+// +kubebuilder:validation:Enum=DryRun;Enforce
+type OrphanCollectionMode string
+
+const (
+	// OrphanCollectionModeDryRun reports orphaned ManifestWorks without deleting them.
+	OrphanCollectionModeDryRun OrphanCollectionMode = "DryRun"
+	// OrphanCollectionModeEnforce deletes orphaned ManifestWorks.
+	OrphanCollectionModeEnforce OrphanCollectionMode = "Enforce"
+)
+
+// OrphanCollection defines the configuration of the collection of orphaned ManifestWorks.
+type OrphanCollection struct {
+	// Mode defines whether orphaned ManifestWorks are only reported (DryRun) or deleted (Enforce).
+	// +kubebuilder:default:="DryRun"
+	// +optional
+	Mode OrphanCollectionMode `json:"mode,omitempty"`
+
+	// Interval is the interval between two collections. Defaults to 10m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ResourceUpdateStrategy defines the update strategy of a kind of resource deployed on the managed cluster.
+type ResourceUpdateStrategy struct {
+	// Group is the API group of the resource. An empty string indicates the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Resource is the plural name of the resource, e.g. secrets.
+	Resource string `json:"resource"`
+
+	// Type is the strategy used by the work agent to update the resource.
+	// +kubebuilder:validation:Enum=Update;CreateOnly;ServerSideApply;ReadOnly
+	Type workv1.UpdateStrategyType `json:"type"`
+
+	// Force indicates whether to force the apply of the resource on conflicts.
+	// It is honored only when the Type is ServerSideApply.
+	// +optional
+	Force bool `json:"force,omitempty"`
 }
 
 // RCSConfigStatus defines the observed state of RCSConfig
@@ -21,9 +21,225 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployFreeze) DeepCopyInto(out *DeployFreeze) {
+	*out = *in
+	out.TimeWindow = in.TimeWindow
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployFreeze.
+func (in *DeployFreeze) DeepCopy() *DeployFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainDelegation) DeepCopyInto(out *DomainDelegation) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainDelegation.
+func (in *DomainDelegation) DeepCopy() *DomainDelegation {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainDelegation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameDNSCheck) DeepCopyInto(out *HostnameDNSCheck) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameDNSCheck.
+func (in *HostnameDNSCheck) DeepCopy() *HostnameDNSCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameDNSCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDigestResolution) DeepCopyInto(out *ImageDigestResolution) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDigestResolution.
+func (in *ImageDigestResolution) DeepCopy() *ImageDigestResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDigestResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePolicy) DeepCopyInto(out *ImagePolicy) {
+	*out = *in
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exceptions != nil {
+		in, out := &in.Exceptions, &out.Exceptions
+		*out = make([]ImagePolicyException, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePolicy.
+func (in *ImagePolicy) DeepCopy() *ImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePolicyException) DeepCopyInto(out *ImagePolicyException) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePolicyException.
+func (in *ImagePolicyException) DeepCopy() *ImagePolicyException {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePolicyException)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.TimeWindow = in.TimeWindow
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacePropagation) DeepCopyInto(out *NamespacePropagation) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacePropagation.
+func (in *NamespacePropagation) DeepCopy() *NamespacePropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacePropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanCollection) DeepCopyInto(out *OrphanCollection) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanCollection.
+func (in *OrphanCollection) DeepCopy() *OrphanCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RCSConfig) DeepCopyInto(out *RCSConfig) {
 	*out = *in
@@ -97,6 +313,96 @@ func (in *RCSConfigSpec) DeepCopyInto(out *RCSConfigSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HostnameDNSCheck != nil {
+		in, out := &in.HostnameDNSCheck, &out.HostnameDNSCheck
+		*out = new(HostnameDNSCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DomainDelegations != nil {
+		in, out := &in.DomainDelegations, &out.DomainDelegations
+		*out = make([]DomainDelegation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateStrategies != nil {
+		in, out := &in.UpdateStrategies, &out.UpdateStrategies
+		*out = make([]ResourceUpdateStrategy, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManifestWorkSizeLimit != nil {
+		in, out := &in.ManifestWorkSizeLimit, &out.ManifestWorkSizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OrphanCollection != nil {
+		in, out := &in.OrphanCollection, &out.OrphanCollection
+		*out = new(OrphanCollection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionTimeout != nil {
+		in, out := &in.DeletionTimeout, &out.DeletionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BreakGlassGroups != nil {
+		in, out := &in.BreakGlassGroups, &out.BreakGlassGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeployFreezes != nil {
+		in, out := &in.DeployFreezes, &out.DeployFreezes
+		*out = make([]DeployFreeze, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NamespacePropagation != nil {
+		in, out := &in.NamespacePropagation, &out.NamespacePropagation
+		*out = new(NamespacePropagation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceCeilings != nil {
+		in, out := &in.ResourceCeilings, &out.ResourceCeilings
+		*out = new(ResourceCeilings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceProfiles != nil {
+		in, out := &in.ResourceProfiles, &out.ResourceProfiles
+		*out = make([]ResourceProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageDigestResolution != nil {
+		in, out := &in.ImageDigestResolution, &out.ImageDigestResolution
+		*out = new(ImageDigestResolution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(ImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PolicySeverities != nil {
+		in, out := &in.PolicySeverities, &out.PolicySeverities
+		*out = make(map[string]PolicySeverity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RCSConfigSpec.
@@ -123,3 +429,94 @@ func (in *RCSConfigStatus) DeepCopy() *RCSConfigStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCeilings) DeepCopyInto(out *ResourceCeilings) {
+	*out = *in
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Capp != nil {
+		in, out := &in.Capp, &out.Capp
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCeilings.
+func (in *ResourceCeilings) DeepCopy() *ResourceCeilings {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCeilings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceProfile) DeepCopyInto(out *ResourceProfile) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.DefaultResources.DeepCopyInto(&out.DefaultResources)
+	if in.Ceilings != nil {
+		in, out := &in.Ceilings, &out.Ceilings
+		*out = new(ResourceCeilings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceProfile.
+func (in *ResourceProfile) DeepCopy() *ResourceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceUpdateStrategy) DeepCopyInto(out *ResourceUpdateStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUpdateStrategy.
+func (in *ResourceUpdateStrategy) DeepCopy() *ResourceUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
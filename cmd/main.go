@@ -17,7 +17,9 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"net"
 	"os"
 
 	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
@@ -25,6 +27,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	orphanctrl "github.com/dana-team/rcs-ocm-deployer/internal/orphans/controller"
 	placementctrl "github.com/dana-team/rcs-ocm-deployer/internal/placement/controller"
 	syncctrl "github.com/dana-team/rcs-ocm-deployer/internal/sync/controller"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -129,16 +132,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = mgr.Add(&orphanctrl.OrphanCollector{
+		Client:        mgr.GetClient(),
+		EventRecorder: mgr.GetEventRecorderFor("orphan-collector"),
+	}); err != nil {
+		setupLog.Error(err, "unable to create collector", "collector", "OrphanCollector")
+		os.Exit(1)
+	}
+
 	hookServer := mgr.GetWebhookServer()
 	decoder := admission.NewDecoder(scheme)
+	if err = rcswebhooks.IndexCappsByHostname(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to index Capps by hostname")
+		os.Exit(1)
+	}
 	hookServer.Register(rcswebhooks.ValidatorServingPath, &webhook.Admission{Handler: &rcswebhooks.CappValidator{
-		Client:  mgr.GetClient(),
-		Decoder: decoder,
+		Client:          mgr.GetClient(),
+		Decoder:         decoder,
+		HostnameChecker: rcswebhooks.NewDNSHostnameChecker(net.DefaultResolver.LookupHost, rcswebhooks.DefaultDNSCacheTTL),
 	}})
 
 	hookServer.Register(rcswebhooks.MutatorServingPath, &webhook.Admission{Handler: &rcswebhooks.CappMutator{
-		Client:  mgr.GetClient(),
-		Decoder: decoder,
+		Client:         mgr.GetClient(),
+		Decoder:        decoder,
+		DigestResolver: rcswebhooks.NewRemoteDigestResolver(),
+	}})
+
+	hookServer.Register(rcswebhooks.ManifestWorkValidatorServingPath, &webhook.Admission{Handler: &rcswebhooks.ManifestWorkValidator{
+		Client:             mgr.GetClient(),
+		Decoder:            decoder,
+		ControllerUsername: rcswebhooks.GetControllerUsername(os.Getenv("POD_NAMESPACE"), os.Getenv("SERVICE_ACCOUNT_NAME")),
 	}})
 
 	//+kubebuilder:scaffold:builder
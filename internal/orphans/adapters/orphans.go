@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultOrphanCollectionInterval is the interval between two collections when none is configured.
+	DefaultOrphanCollectionInterval = 10 * time.Minute
+
+	// ReasonCappNotFound is the reason of a ManifestWork whose Capp no longer exists.
+	ReasonCappNotFound = "CappNotFound"
+	// ReasonClusterMismatch is the reason of a ManifestWork whose Capp is placed on a different managed cluster.
+	ReasonClusterMismatch = "ClusterMismatch"
+	// ReasonCappRecreated is the reason of a ManifestWork whose Capp was deleted and recreated under the same name.
+	ReasonCappRecreated = "CappRecreated"
+
+	// legacyManifestWorkPrefix is the name prefix of the ManifestWorks created before they were labeled with their Capp.
+	legacyManifestWorkPrefix = "mw-create-"
+)
+
+// OrphanedManifestWork is a ManifestWork deploying a Capp which should no longer be deployed on its managed cluster.
+type OrphanedManifestWork struct {
+	ManifestWork workv1.ManifestWork
+	Reason       string
+}
+
+// GetOrphanCollectionMode returns the orphan collection mode of the RCS Config, defaulting to DryRun.
+func GetOrphanCollectionMode(config rcsv1alpha1.RCSConfig) rcsv1alpha1.OrphanCollectionMode {
+	if config.Spec.OrphanCollection == nil || config.Spec.OrphanCollection.Mode == "" {
+		return rcsv1alpha1.OrphanCollectionModeDryRun
+	}
+	return config.Spec.OrphanCollection.Mode
+}
+
+// GetOrphanCollectionInterval returns the orphan collection interval of the RCS Config,
+// defaulting to DefaultOrphanCollectionInterval.
+func GetOrphanCollectionInterval(config rcsv1alpha1.RCSConfig) time.Duration {
+	if config.Spec.OrphanCollection == nil || config.Spec.OrphanCollection.Interval == nil || config.Spec.OrphanCollection.Interval.Duration <= 0 {
+		return DefaultOrphanCollectionInterval
+	}
+	return config.Spec.OrphanCollection.Interval.Duration
+}
+
+// FindOrphanedManifestWorks returns the ManifestWorks managed by rcs whose Capp no longer exists, was recreated,
+// or is now placed on a different managed cluster than the one of the ManifestWork namespace. ManifestWorks created
+// before they were labeled with their Capp are matched by the Capp they deploy. ManifestWorks which are being deleted,
+// and those of Capps which are being deleted, are left to the sync controller.
+func FindOrphanedManifestWorks(ctx context.Context, k8sClient client.Client) ([]OrphanedManifestWork, error) {
+	mwList := workv1.ManifestWorkList{}
+	if err := k8sClient.List(ctx, &mwList); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks: %v", err.Error())
+	}
+
+	var orphans []OrphanedManifestWork
+	for _, mw := range mwList.Items {
+		if mw.DeletionTimestamp != nil {
+			continue
+		}
+		key, uid, ok := getManifestWorkCapp(mw)
+		if !ok {
+			continue
+		}
+
+		capp := cappv1alpha1.Capp{}
+		if err := k8sClient.Get(ctx, key, &capp); err != nil {
+			if errors.IsNotFound(err) {
+				orphans = append(orphans, OrphanedManifestWork{ManifestWork: mw, Reason: ReasonCappNotFound})
+				continue
+			}
+			return nil, fmt.Errorf("failed to get Capp %q of ManifestWork %q: %v", key.Name, mw.Name, err.Error())
+		}
+		if capp.DeletionTimestamp != nil {
+			continue
+		}
+
+		cluster := capp.Annotations[utils.AnnotationKeyHasPlacement]
+		if cluster != "" && cluster != mw.Namespace {
+			orphans = append(orphans, OrphanedManifestWork{ManifestWork: mw, Reason: ReasonClusterMismatch})
+			continue
+		}
+		// The ManifestWorks of a recreated Capp placed on the same cluster are adopted by the sync controller
+		if uid != "" && uid != string(capp.UID) && cluster != mw.Namespace {
+			orphans = append(orphans, OrphanedManifestWork{ManifestWork: mw, Reason: ReasonCappRecreated})
+		}
+	}
+	return orphans, nil
+}
+
+// getManifestWorkCapp returns the namespaced name and the UID of the Capp the ManifestWork belongs to, and whether
+// it belongs to a Capp. ManifestWorks managed by rcs are matched by their labels, and legacy ManifestWorks, which
+// are not labeled and whose UID is unknown, are matched by the Capp manifest they contain.
+func getManifestWorkCapp(mw workv1.ManifestWork) (types.NamespacedName, string, bool) {
+	if mw.Labels[utils.MangedByLableKey] == utils.MangedByLabelValue {
+		name, namespace := mw.Labels[utils.CappNameLabelKey], mw.Labels[utils.CappNamespaceLabelKey]
+		return types.NamespacedName{Name: name, Namespace: namespace}, mw.Labels[utils.CappUIDLabelKey], name != "" && namespace != ""
+	}
+	if _, labeled := mw.Labels[utils.CappNameLabelKey]; labeled || !strings.HasPrefix(mw.Name, legacyManifestWorkPrefix) {
+		return types.NamespacedName{}, "", false
+	}
+
+	for _, manifest := range mw.Spec.Workload.Manifests {
+		obj := unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			continue
+		}
+		if obj.GetKind() == "Capp" && obj.GetName() != "" && obj.GetNamespace() != "" {
+			return types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, "", true
+		}
+	}
+	return types.NamespacedName{}, "", false
+}
@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newManifestWork(name, cluster, cappName string) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster,
+			Labels: map[string]string{
+				utils.MangedByLableKey:      utils.MangedByLabelValue,
+				utils.CappNameLabelKey:      cappName,
+				utils.CappNamespaceLabelKey: "test-namespace",
+			},
+		},
+	}
+}
+
+// newLegacyManifestWork returns an unlabeled ManifestWork deploying the specified Capp, as created before
+// ManifestWorks were labeled with their Capp.
+func newLegacyManifestWork(name, cluster, cappName string) *workv1.ManifestWork {
+	capp := fmt.Sprintf(`{"apiVersion":"rcs.dana.io/v1alpha1","kind":"Capp","metadata":{"name":%q,"namespace":"test-namespace"}}`, cappName)
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster},
+		Spec: workv1.ManifestWorkSpec{Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{
+			{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"test-namespace"}}`)}},
+			{RawExtension: runtime.RawExtension{Raw: []byte(capp)}},
+		}}},
+	}
+}
+
+func TestFindOrphanedManifestWorks(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, workv1.AddToScheme(scheme))
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-capp",
+			Namespace:   "test-namespace",
+			UID:         "test-uid",
+			Annotations: map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"},
+		},
+	}
+	unmanagedMW := newManifestWork("unmanaged", "cluster1", "missing-capp")
+	unmanagedMW.Labels = nil
+	recreatedMW := newManifestWork("recreated", "cluster2", "test-capp")
+	recreatedMW.Labels[utils.CappUIDLabelKey] = "old-uid"
+	adoptedMW := newManifestWork("adopted", "cluster1", "test-capp")
+	adoptedMW.Labels[utils.CappUIDLabelKey] = "old-uid"
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		capp,
+		newManifestWork("placed", "cluster1", "test-capp"),
+		newManifestWork("moved", "cluster2", "test-capp"),
+		newManifestWork("deleted", "cluster1", "missing-capp"),
+		unmanagedMW,
+		recreatedMW,
+		adoptedMW,
+		newLegacyManifestWork("mw-create-test-namespace-missing-capp", "cluster1", "missing-capp"),
+		newLegacyManifestWork("mw-create-test-namespace-test-capp", "cluster1", "test-capp"),
+		newLegacyManifestWork("mw-create-test-namespace-test-capp", "cluster2", "test-capp"),
+	).Build()
+
+	orphans, err := FindOrphanedManifestWorks(ctx, fakeClient)
+	assert.NoError(t, err)
+
+	// Assert that only the ManifestWorks of a missing Capp or of a Capp placed on another cluster are orphaned,
+	// including legacy ManifestWorks, which are matched by the Capp they deploy
+	reasons := map[string]string{}
+	for _, orphan := range orphans {
+		reasons[orphan.ManifestWork.Namespace+"/"+orphan.ManifestWork.Name] = orphan.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"cluster2/moved":     ReasonClusterMismatch,
+		"cluster1/deleted":   ReasonCappNotFound,
+		"cluster2/recreated": ReasonClusterMismatch,
+		"cluster1/mw-create-test-namespace-missing-capp": ReasonCappNotFound,
+		"cluster2/mw-create-test-namespace-test-capp":    ReasonClusterMismatch,
+	}, reasons)
+
+	// Assert that the ManifestWorks of a Capp recreated under the same name are orphaned until the Capp is placed
+	capp.Annotations = nil
+	assert.NoError(t, fakeClient.Update(ctx, capp))
+	orphans, err = FindOrphanedManifestWorks(ctx, fakeClient)
+	assert.NoError(t, err)
+	reasons = map[string]string{}
+	for _, orphan := range orphans {
+		reasons[orphan.ManifestWork.Namespace+"/"+orphan.ManifestWork.Name] = orphan.Reason
+	}
+	assert.Equal(t, ReasonCappRecreated, reasons["cluster1/adopted"])
+	assert.Equal(t, ReasonCappRecreated, reasons["cluster2/recreated"])
+	assert.NotContains(t, reasons, "cluster1/placed")
+}
+
+func TestGetOrphanCollectionDefaults(t *testing.T) {
+	config := rcsv1alpha1.RCSConfig{}
+
+	// Assert that the collection defaults to reporting every 10 minutes
+	assert.Equal(t, rcsv1alpha1.OrphanCollectionModeDryRun, GetOrphanCollectionMode(config))
+	assert.Equal(t, DefaultOrphanCollectionInterval, GetOrphanCollectionInterval(config))
+
+	config.Spec.OrphanCollection = &rcsv1alpha1.OrphanCollection{
+		Mode:     rcsv1alpha1.OrphanCollectionModeEnforce,
+		Interval: &metav1.Duration{Duration: time.Minute},
+	}
+	assert.Equal(t, rcsv1alpha1.OrphanCollectionModeEnforce, GetOrphanCollectionMode(config))
+	assert.Equal(t, time.Minute, GetOrphanCollectionInterval(config))
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/orphans/adapters"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils/events"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils/metrics"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// collectorName is the name of the orphan collector
+const collectorName = "OrphanCollector"
+
+// OrphanCollector periodically collects the ManifestWorks managed by rcs whose Capp no longer exists,
+// or whose Capp is placed on a different managed cluster. Depending on the mode in the RCS Config,
+// orphaned ManifestWorks are either only reported or deleted.
+type OrphanCollector struct {
+	client.Client
+	EventRecorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=rcs.dana.io,resources=capps,verbs=get;list;watch
+
+// Start runs the collection every interval configured in the RCS Config, until the context is done.
+func (c *OrphanCollector) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName(collectorName)
+	for {
+		config, err := c.getRCSConfig(ctx)
+		if err != nil {
+			logger.Error(err, "failed to get RCS Config")
+			config = &rcsv1alpha1.RCSConfig{}
+		} else if err := c.Collect(ctx, adapters.GetOrphanCollectionMode(*config), logger); err != nil {
+			logger.Error(err, "failed to collect orphaned ManifestWorks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(adapters.GetOrphanCollectionInterval(*config)):
+		}
+	}
+}
+
+// NeedLeaderElection makes sure only the leader collects orphaned ManifestWorks.
+func (c *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect finds the orphaned ManifestWorks and records them in metrics and events.
+// The orphaned ManifestWorks are deleted only in Enforce mode.
+func (c *OrphanCollector) Collect(ctx context.Context, mode rcsv1alpha1.OrphanCollectionMode, logger logr.Logger) error {
+	orphans, err := adapters.FindOrphanedManifestWorks(ctx, c.Client)
+	if err != nil {
+		return err
+	}
+
+	for _, reason := range []string{adapters.ReasonCappNotFound, adapters.ReasonClusterMismatch} {
+		metrics.OrphanedManifestWorks.WithLabelValues(reason).Set(0)
+	}
+	for _, orphan := range orphans {
+		mw := orphan.ManifestWork
+		metrics.OrphanedManifestWorks.WithLabelValues(orphan.Reason).Inc()
+		message := fmt.Sprintf("ManifestWork %q of Capp %q in namespace %q is orphaned: %s", mw.Name,
+			mw.Labels[utils.CappNameLabelKey], mw.Labels[utils.CappNamespaceLabelKey], orphan.Reason)
+		logger.Info(message, "mode", mode)
+
+		if mode != rcsv1alpha1.OrphanCollectionModeEnforce {
+			c.EventRecorder.Event(&mw, corev1.EventTypeWarning, events.EventManifestWorkOrphaned, message)
+			continue
+		}
+		if err := c.Delete(ctx, &mw); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned ManifestWork %q: %v", mw.Name, err.Error())
+		}
+		metrics.OrphanedManifestWorkDeletions.Inc()
+		c.EventRecorder.Event(&mw, corev1.EventTypeNormal, events.EventOrphanedManifestWorkDeleted, message)
+	}
+	return nil
+}
+
+// getRCSConfig returns the RCS Config, or an empty RCS Config if it has not been defined.
+func (c *OrphanCollector) getRCSConfig(ctx context.Context) (*rcsv1alpha1.RCSConfig, error) {
+	config, err := utils.GetRCSConfig(ctx, c.Client)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &rcsv1alpha1.RCSConfig{}, nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
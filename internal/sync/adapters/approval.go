@@ -0,0 +1,78 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxPendingDiffLength is the maximal length of the pending diff of a Capp, so that it fits in an event.
+const maxPendingDiffLength = 1000
+
+// IsApprovalRequired checks if changes to the placed Capps of the namespace are held until they are approved,
+// which is the case when the namespace has the approval-required label.
+func IsApprovalRequired(ctx context.Context, k8sClient client.Client, namespace string) (bool, error) {
+	ns := corev1.Namespace{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return false, fmt.Errorf("failed to get namespace %q: %v", namespace, err.Error())
+	}
+	return ns.Labels[utils.ApprovalRequiredLabelKey] == "true", nil
+}
+
+// IsCappApproved checks if the current generation of the Capp has been approved.
+func IsCappApproved(capp cappv1alpha1.Capp) bool {
+	return capp.Annotations[utils.AnnotationKeyApprovedGeneration] == strconv.FormatInt(capp.Generation, 10)
+}
+
+// GeneratePendingDiff returns the diff between the spec of the Capp in its latest revision and its current spec,
+// truncated at a line boundary to fit in an event. If the Capp has no revisions, an empty diff is returned.
+func GeneratePendingDiff(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp) (string, error) {
+	revisions, err := ListRevisions(ctx, k8sClient, capp)
+	if err != nil {
+		return "", err
+	}
+	if len(revisions) == 0 {
+		return "", nil
+	}
+
+	data := RevisionData{}
+	if err := json.Unmarshal(revisions[len(revisions)-1].Data.Raw, &data); err != nil {
+		return "", fmt.Errorf("failed to unmarshal revision %q: %v", revisions[len(revisions)-1].Name, err.Error())
+	}
+	shipped, err := toUnstructuredSpec(data.Spec)
+	if err != nil {
+		return "", err
+	}
+	pending, err := toUnstructuredSpec(capp.Spec)
+	if err != nil {
+		return "", err
+	}
+
+	diff := cmp.Diff(shipped, pending)
+	if len(diff) > maxPendingDiffLength {
+		diff = diff[:strings.LastIndex(diff[:maxPendingDiffLength], "\n")+1] + "... (truncated)"
+	}
+	return diff, nil
+}
+
+// toUnstructuredSpec returns the spec of a Capp as a map, so that it can be compared regardless of
+// the unexported fields of its types.
+func toUnstructuredSpec(spec cappv1alpha1.CappSpec) (map[string]interface{}, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Capp spec: %v", err.Error())
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Capp spec: %v", err.Error())
+	}
+	return object, nil
+}
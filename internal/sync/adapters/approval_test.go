@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsApprovalRequired(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	production := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "production", Labels: map[string]string{utils.ApprovalRequiredLabelKey: "true"}}}
+	development := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "development"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(production, development).Build()
+
+	required, err := IsApprovalRequired(ctx, fakeClient, "production")
+	assert.NoError(t, err)
+	assert.True(t, required)
+
+	required, err = IsApprovalRequired(ctx, fakeClient, "development")
+	assert.NoError(t, err)
+	assert.False(t, required)
+
+	_, err = IsApprovalRequired(ctx, fakeClient, "missing")
+	assert.Error(t, err)
+}
+
+func TestIsCappApproved(t *testing.T) {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	assert.False(t, IsCappApproved(capp))
+
+	capp.Annotations = map[string]string{utils.AnnotationKeyApprovedGeneration: "2"}
+	assert.False(t, IsCappApproved(capp))
+
+	capp.Annotations[utils.AnnotationKeyApprovedGeneration] = "3"
+	assert.True(t, IsCappApproved(capp))
+}
+
+func TestGeneratePendingDiff(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace", UID: "test-uid"},
+		Spec:       cappv1alpha1.CappSpec{Site: "cluster1"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(capp).Build()
+
+	// Assert that there is no diff to show before a revision has been recorded
+	diff, err := GeneratePendingDiff(ctx, fakeClient, *capp)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	// Assert that the diff shows the changes since the latest revision
+	assert.NoError(t, RecordRevision(ctx, fakeClient, scheme, *capp, nil, DefaultRevisionHistoryLimit))
+	capp.Spec.Site = "cluster2"
+	diff, err = GeneratePendingDiff(ctx, fakeClient, *capp)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, `"cluster1"`)
+	assert.Contains(t, diff, `"cluster2"`)
+
+	// Assert that long diffs are truncated
+	for i := 0; i < 100; i++ {
+		capp.Spec.ConfigurationSpec.Template.Spec.Containers = append(capp.Spec.ConfigurationSpec.Template.Spec.Containers,
+			corev1.Container{Name: fmt.Sprintf("container-%d", i), Image: "registry.example.com/app"})
+	}
+	diff, err = GeneratePendingDiff(ctx, fakeClient, *capp)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(diff, "(truncated)"))
+	assert.LessOrEqual(t, len(diff), maxPendingDiffLength+len("... (truncated)"))
+}
@@ -3,30 +3,47 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"time"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
-	v1 "open-cluster-management.io/api/work/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const FinalizerCleanupCapp = "dana.io/capp-cleanup"
 
+// DefaultDeletionTimeout is the duration to wait for the ManifestWorks of a deleted Capp to be removed
+// from the managed clusters when no timeout is configured.
+const DefaultDeletionTimeout = 10 * time.Minute
+
+// GetDeletionTimeout returns the deletion timeout of the RCS Config, defaulting to DefaultDeletionTimeout.
+func GetDeletionTimeout(config rcsv1alpha1.RCSConfig) time.Duration {
+	if config.Spec.DeletionTimeout == nil || config.Spec.DeletionTimeout.Duration <= 0 {
+		return DefaultDeletionTimeout
+	}
+	return config.Spec.DeletionTimeout.Duration
+}
+
 // HandleCappDeletion handles the deletion of a Capp custom resource. It checks if the resource has a deletion timestamp
 // and contains the specified finalizer. If so, it finalizes the Capp by cleaning up associated resources.
 // It removes the finalizer once cleanup is complete and updates the resource.
-func HandleCappDeletion(ctx context.Context, capp cappv1alpha1.Capp, log logr.Logger, r client.Client) error {
+func HandleCappDeletion(ctx context.Context, capp cappv1alpha1.Capp, timeout time.Duration, log logr.Logger, r client.Client) error {
 	if controllerutil.ContainsFinalizer(&capp, FinalizerCleanupCapp) {
-		mwName := GenerateMWName(capp)
-		if err := finalizeCapp(ctx, mwName, capp.Status.ApplicationLinks.Site, log, r); err != nil {
-			if errors.IsNotFound(err) {
-				return removeFinalizer(ctx, capp, log, r)
-			}
+		finalized, err := finalizeCapp(ctx, capp, timeout, log, r)
+		if err != nil {
 			return err
 		}
+		if finalized {
+			return removeFinalizer(ctx, capp, log, r)
+		}
 	}
 	return nil
 }
@@ -41,19 +58,93 @@ func removeFinalizer(ctx context.Context, capp cappv1alpha1.Capp, log logr.Logge
 	return nil
 }
 
-// finalizeCapp deletes the ManifestWork associated with the Capp on the specified managed cluster.
-// The function gets the context, manifest work name, managed cluster name, and logger.
-func finalizeCapp(ctx context.Context, mwName string, managedClusterName string, log logr.Logger, r client.Client) error {
-	var work v1.ManifestWork
-	if err := r.Get(ctx, types.NamespacedName{Name: mwName, Namespace: managedClusterName}, &work); err != nil {
-		return err
+// finalizeCapp deletes all the ManifestWorks associated with the Capp across all managed clusters. It returns true
+// only once no ManifestWork of the Capp is left, which happens after the work agents removed the resources of the Capp
+// from the managed clusters. Until then, the progress is reported in the Terminating condition of the Capp.
+// If the Capp has the force-orphan annotation, the resources are orphaned on the managed clusters instead.
+func finalizeCapp(ctx context.Context, capp cappv1alpha1.Capp, timeout time.Duration, log logr.Logger, r client.Client) (bool, error) {
+	works, err := listManifestWorksForDeletion(ctx, capp, r)
+	if err != nil {
+		return false, err
 	}
-	log.Info("Trying to delete ManifestWork", "ManifestWork", work.Name)
-	if err := r.Delete(ctx, &work); err != nil {
-		return fmt.Errorf("unable to delete ManifestWork: %v", err.Error())
+	if len(works) == 0 {
+		return true, nil
 	}
-	log.Info("Deleted manifest work successfully")
-	return nil
+
+	forceOrphan := capp.Annotations[utils.AnnotationKeyForceOrphan] == "true"
+	for _, work := range works {
+		if forceOrphan {
+			log.Info("Orphaning resources of ManifestWork", "ManifestWork", work.Name, "ManagedCluster", work.Namespace)
+			if err := orphanManifestWork(ctx, r, work, true); err != nil {
+				return false, err
+			}
+		}
+		if work.DeletionTimestamp != nil {
+			continue
+		}
+		log.Info("Trying to delete ManifestWork", "ManifestWork", work.Name, "ManagedCluster", work.Namespace)
+		if err := r.Delete(ctx, &work); err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("unable to delete ManifestWork: %v", err.Error())
+		}
+		log.Info("Deleted manifest work successfully")
+	}
+
+	return false, SetCappCondition(ctx, r, &capp, generateTerminatingCondition(capp, works, timeout, forceOrphan))
+}
+
+// listManifestWorksForDeletion returns all the ManifestWorks of the Capp across all managed clusters, including
+// those created under the legacy naming scheme on the managed clusters the Capp is known to be placed on.
+func listManifestWorksForDeletion(ctx context.Context, capp cappv1alpha1.Capp, r client.Client) ([]workv1.ManifestWork, error) {
+	works, err := ListCappManifestWorks(ctx, r, capp)
+	if err != nil {
+		return nil, err
+	}
+	for _, managedClusterName := range getCappManagedClusters(capp) {
+		legacyWorks, err := ListLegacyManifestWorks(ctx, r, capp, managedClusterName)
+		if err != nil {
+			return nil, err
+		}
+		works = append(works, legacyWorks...)
+	}
+	return works, nil
+}
+
+// getCappManagedClusters returns the names of the managed clusters the Capp is known to be placed on,
+// based on both its placement annotation and its status.
+func getCappManagedClusters(capp cappv1alpha1.Capp) []string {
+	var managedClusters []string
+	for _, managedClusterName := range []string{capp.Annotations[utils.AnnotationKeyHasPlacement], capp.Status.ApplicationLinks.Site} {
+		if managedClusterName != "" && !slices.Contains(managedClusters, managedClusterName) {
+			managedClusters = append(managedClusters, managedClusterName)
+		}
+	}
+	return managedClusters
+}
+
+// generateTerminatingCondition returns the Terminating condition of a Capp whose specified ManifestWorks
+// are still being deleted from the managed clusters.
+func generateTerminatingCondition(capp cappv1alpha1.Capp, works []workv1.ManifestWork, timeout time.Duration, forceOrphan bool) metav1.Condition {
+	var names []string
+	for _, work := range works {
+		names = append(names, work.Namespace+"/"+work.Name)
+	}
+	sort.Strings(names)
+
+	condition := metav1.Condition{
+		Type:    ConditionTypeTerminating,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonWaitingForManagedClusters,
+		Message: fmt.Sprintf("Waiting for ManifestWorks %v to be removed from the managed clusters", names),
+	}
+	if forceOrphan {
+		condition.Reason = ReasonForceOrphaned
+		condition.Message = fmt.Sprintf("Orphaning the resources of ManifestWorks %v on the managed clusters", names)
+	} else if capp.DeletionTimestamp != nil && time.Since(capp.DeletionTimestamp.Time) > timeout {
+		condition.Reason = ReasonDeletionTimedOut
+		condition.Message = fmt.Sprintf("ManifestWorks %v were not removed from the managed clusters within %v; "+
+			"set the %q annotation to \"true\" to orphan their resources", names, timeout, utils.AnnotationKeyForceOrphan)
+	}
+	return condition
 }
 
 // EnsureFinalizer ensures the Capp has the finalizer specified (FinalizerCleanupCapp).
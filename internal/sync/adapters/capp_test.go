@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const workAgentFinalizer = "cluster.open-cluster-management.io/manifest-work-cleanup"
+
+func TestHandleCappDeletion(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, workv1.AddToScheme(scheme))
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-capp",
+			Namespace:         "test-namespace",
+			Finalizers:        []string{FinalizerCleanupCapp},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			Annotations:       map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"},
+		},
+	}
+	mws, err := GenerateManifestWorks(*capp, "cluster1", [][]workv1.Manifest{{}}, nil)
+	assert.NoError(t, err)
+	mw := mws[0]
+	mw.Finalizers = []string{workAgentFinalizer}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(capp, mw).
+		WithStatusSubresource(&cappv1alpha1.Capp{}).
+		WithIndex(&workv1.ManifestWork{}, CappIndexKey, ManifestWorkCappIndexFunc).
+		Build()
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+
+	// Assert that the Capp waits for the ManifestWork to be removed from the managed cluster
+	assert.NoError(t, HandleCappDeletion(ctx, getCapp(), time.Hour, logr.Discard(), fakeClient))
+	current := getCapp()
+	assert.Contains(t, current.Finalizers, FinalizerCleanupCapp)
+	condition := meta.FindStatusCondition(current.Status.Conditions, ConditionTypeTerminating)
+	assert.NotNil(t, condition)
+	assert.Equal(t, ReasonWaitingForManagedClusters, condition.Reason)
+
+	deletingMW := workv1.ManifestWork{}
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(mw), &deletingMW))
+	assert.NotNil(t, deletingMW.DeletionTimestamp)
+
+	// Assert that the deletion is reported as timed out once the timeout passed
+	assert.NoError(t, HandleCappDeletion(ctx, getCapp(), 0, logr.Discard(), fakeClient))
+	condition = meta.FindStatusCondition(getCapp().Status.Conditions, ConditionTypeTerminating)
+	assert.Equal(t, ReasonDeletionTimedOut, condition.Reason)
+
+	// Assert that the force-orphan annotation orphans the resources and lets the Capp be deleted
+	current = getCapp()
+	current.Annotations[utils.AnnotationKeyForceOrphan] = "true"
+	assert.NoError(t, fakeClient.Update(ctx, &current))
+	assert.NoError(t, HandleCappDeletion(ctx, getCapp(), time.Hour, logr.Discard(), fakeClient))
+	assert.Error(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(mw), &workv1.ManifestWork{}))
+
+	assert.NoError(t, HandleCappDeletion(ctx, getCapp(), time.Hour, logr.Discard(), fakeClient))
+	assert.Error(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &cappv1alpha1.Capp{}))
+}
@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeTerminating is the type of the condition reporting the progress of the deletion of a Capp
+	// from the managed clusters.
+	ConditionTypeTerminating = "Terminating"
+
+	// ReasonWaitingForManagedClusters is the reason of the Terminating condition while the ManifestWorks of the Capp
+	// are being deleted from the managed clusters.
+	ReasonWaitingForManagedClusters = "WaitingForManagedClusters"
+	// ReasonDeletionTimedOut is the reason of the Terminating condition when the ManifestWorks of the Capp
+	// were not deleted from the managed clusters within the deletion timeout.
+	ReasonDeletionTimedOut = "DeletionTimedOut"
+	// ReasonForceOrphaned is the reason of the Terminating condition when the resources of the Capp
+	// are orphaned on the managed clusters.
+	ReasonForceOrphaned = "ForceOrphaned"
+
+	// ConditionTypeSyncPaused is the type of the condition reporting that the sync of the Capp
+	// to its managed cluster is paused.
+	ConditionTypeSyncPaused = "SyncPaused"
+
+	// ReasonPausedByAnnotation is the reason of the SyncPaused condition when the sync is paused using an annotation.
+	ReasonPausedByAnnotation = "PausedByAnnotation"
+
+	// ConditionTypePending is the type of the condition reporting that updates of the Capp are deferred.
+	ConditionTypePending = "Pending"
+
+	// ReasonDeployFreeze is the reason of the Pending condition when updates are deferred by a deploy freeze.
+	ReasonDeployFreeze = "DeployFreeze"
+	// ReasonOutsideMaintenanceWindow is the reason of the Pending condition when updates are deferred
+	// until the next maintenance window.
+	ReasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+
+	// ConditionTypeApprovalPending is the type of the condition reporting that changes to the Capp are held
+	// until they are approved.
+	ConditionTypeApprovalPending = "ApprovalPending"
+
+	// ReasonAwaitingApproval is the reason of the ApprovalPending condition while the generation of the Capp
+	// has not been approved.
+	ReasonAwaitingApproval = "AwaitingApproval"
+)
+
+// SetCappCondition sets the specified condition on the status of the Capp. The status is only updated
+// when the condition changed, so that reconciling an unchanged Capp does not cause status churn.
+func SetCappCondition(ctx context.Context, k8sClient client.Client, capp *cappv1alpha1.Capp, condition metav1.Condition) error {
+	condition.ObservedGeneration = capp.Generation
+	if !meta.SetStatusCondition(&capp.Status.Conditions, condition) {
+		return nil
+	}
+	if err := k8sClient.Status().Update(ctx, capp); err != nil {
+		return fmt.Errorf("failed to set condition %q on Capp: %v", condition.Type, err.Error())
+	}
+	return nil
+}
+
+// RemoveCappCondition removes the condition of the specified type from the status of the Capp,
+// updating the status only when the condition exists.
+func RemoveCappCondition(ctx context.Context, k8sClient client.Client, capp *cappv1alpha1.Capp, conditionType string) error {
+	if !meta.RemoveStatusCondition(&capp.Status.Conditions, conditionType) {
+		return nil
+	}
+	if err := k8sClient.Status().Update(ctx, capp); err != nil {
+		return fmt.Errorf("failed to remove condition %q from Capp: %v", conditionType, err.Error())
+	}
+	return nil
+}
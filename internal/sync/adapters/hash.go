@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+// ManifestsHashAnnotationKey is the key of the annotation which holds the hash of the content
+// a ManifestWork was last applied with.
+const ManifestsHashAnnotationKey = "rcs.dana.io/manifests-hash"
+
+// hashedContent is the content of a ManifestWork which is taken into account when hashing it.
+type hashedContent struct {
+	Labels map[string]string       `json:"labels,omitempty"`
+	Spec   workv1.ManifestWorkSpec `json:"spec"`
+}
+
+// HashManifestWork returns a deterministic hash of the labels and spec of the specified ManifestWork.
+// Every manifest is normalized to its canonical JSON form before hashing, so that manifests with the
+// same content produce the same hash regardless of whether they hold a raw or a typed object.
+func HashManifestWork(mw workv1.ManifestWork) (string, error) {
+	spec := *mw.Spec.DeepCopy()
+	for i, manifest := range spec.Workload.Manifests {
+		raw, err := canonicalizeManifest(manifest)
+		if err != nil {
+			return "", fmt.Errorf("failed to normalize manifest: %v", err.Error())
+		}
+		spec.Workload.Manifests[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}
+	}
+
+	content, err := json.Marshal(hashedContent{Labels: mw.Labels, Spec: spec})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ManifestWork content: %v", err.Error())
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetManifestWorkHashAnnotation sets the hash of the specified ManifestWork in its ManifestsHashAnnotationKey annotation.
+func SetManifestWorkHashAnnotation(mw *workv1.ManifestWork) error {
+	hash, err := HashManifestWork(*mw)
+	if err != nil {
+		return err
+	}
+	if mw.Annotations == nil {
+		mw.Annotations = make(map[string]string)
+	}
+	mw.Annotations[ManifestsHashAnnotationKey] = hash
+	return nil
+}
+
+// IsManifestWorkUpToDate returns whether the existing ManifestWork was last applied with
+// the same content as the desired ManifestWork, according to their hash annotations.
+func IsManifestWorkUpToDate(existing workv1.ManifestWork, desired workv1.ManifestWork) bool {
+	existingHash := existing.Annotations[ManifestsHashAnnotationKey]
+	return existingHash != "" && existingHash == desired.Annotations[ManifestsHashAnnotationKey]
+}
+
+// IsManifestWorkDrifted returns whether the content of the existing ManifestWork no longer matches the content
+// it was last applied with, according to its hash annotation, which happens when it is edited out-of-band.
+func IsManifestWorkDrifted(existing workv1.ManifestWork) (bool, error) {
+	existingHash := existing.Annotations[ManifestsHashAnnotationKey]
+	if existingHash == "" {
+		return false, nil
+	}
+	liveHash, err := HashManifestWork(existing)
+	if err != nil {
+		return false, err
+	}
+	return liveHash != existingHash, nil
+}
+
+// canonicalizeManifest returns the canonical JSON form of a manifest, in which object keys are sorted,
+// null values are removed and insignificant whitespace is removed. Null values are removed since the
+// API server prunes them from the metadata of the manifests when storing the ManifestWork.
+func canonicalizeManifest(manifest workv1.Manifest) ([]byte, error) {
+	raw := manifest.Raw
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(manifest.Object); err != nil {
+			return nil, err
+		}
+	}
+
+	var content interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&content); err != nil {
+		return nil, err
+	}
+	return json.Marshal(removeNullValues(content))
+}
+
+// removeNullValues recursively removes the keys holding null values from the objects in the specified content.
+func removeNullValues(content interface{}) interface{} {
+	switch value := content.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if field == nil {
+				delete(value, key)
+				continue
+			}
+			value[key] = removeNullValues(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = removeNullValues(item)
+		}
+	}
+	return content
+}
@@ -0,0 +1,70 @@
+package adapters
+
+import (
+	"encoding/json"
+	"testing"
+
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+func TestHashManifestWork(t *testing.T) {
+	configMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "test-namespace"},
+		Data:       map[string]string{"key": "value"},
+	}
+	typedManifest := builder.BuildConfigMap(configMap)
+	raw, err := json.Marshal(typedManifest.Object)
+	assert.NoError(t, err)
+	rawManifest := workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}
+
+	typedHash, err := HashManifestWork(*GenerateManifestWorkGeneric("test-mw", "cluster1", []workv1.Manifest{typedManifest}))
+	assert.NoError(t, err)
+	rawHash, err := HashManifestWork(*GenerateManifestWorkGeneric("test-mw", "cluster1", []workv1.Manifest{rawManifest}))
+	assert.NoError(t, err)
+
+	// Assert that typed and raw manifests with the same content have the same hash
+	assert.Equal(t, typedHash, rawHash)
+
+	configMap.Data["key"] = "other-value"
+	changedHash, err := HashManifestWork(*GenerateManifestWorkGeneric("test-mw", "cluster1", []workv1.Manifest{builder.BuildConfigMap(configMap)}))
+	assert.NoError(t, err)
+
+	// Assert that changing the content changes the hash
+	assert.NotEqual(t, typedHash, changedHash)
+}
+
+func TestIsManifestWorkUpToDate(t *testing.T) {
+	desired := GenerateManifestWorkGeneric("test-mw", "cluster1", []workv1.Manifest{builder.BuildNamespace("test-namespace")})
+	assert.NoError(t, SetManifestWorkHashAnnotation(desired))
+
+	// Assert that a ManifestWork without a hash is never up to date
+	assert.False(t, IsManifestWorkUpToDate(workv1.ManifestWork{}, *desired))
+
+	// Assert that a ManifestWork with the same hash is up to date
+	assert.True(t, IsManifestWorkUpToDate(*desired.DeepCopy(), *desired))
+}
+
+func TestIsManifestWorkDrifted(t *testing.T) {
+	mw := GenerateManifestWorkGeneric("test-mw", "cluster1", []workv1.Manifest{builder.BuildNamespace("test-namespace")})
+	assert.NoError(t, SetManifestWorkHashAnnotation(mw))
+
+	// Assert that a ManifestWork whose manifests were stored without their null values has not drifted
+	stored := mw.DeepCopy()
+	stored.Spec.Workload.Manifests[0] = workv1.Manifest{RawExtension: runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"test-namespace","labels":{"rcs.dana.io/managed-by":"rcs"}},"spec":{},"status":{}}`),
+	}}
+	drifted, err := IsManifestWorkDrifted(*stored)
+	assert.NoError(t, err)
+	assert.False(t, drifted)
+
+	// Assert that a ManifestWork whose manifests were edited has drifted
+	stored.Spec.Workload.Manifests[0].Raw = []byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"other-namespace"}}`)
+	drifted, err = IsManifestWorkDrifted(*stored)
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+}
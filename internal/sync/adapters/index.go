@@ -0,0 +1,46 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CappIndexKey is the key of the index of ManifestWorks by the namespace and name of the Capp they belong to.
+const CappIndexKey = "rcs.dana.io/capp"
+
+// IndexManifestWorksByCapp registers an index of ManifestWorks by the namespace and name of the Capp they belong to,
+// so that all the ManifestWorks of a Capp can be listed across all managed cluster namespaces.
+func IndexManifestWorksByCapp(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &workv1.ManifestWork{}, CappIndexKey, ManifestWorkCappIndexFunc)
+}
+
+// ManifestWorkCappIndexFunc returns the value of the CappIndexKey index of the specified ManifestWork,
+// based on its Capp labels. ManifestWorks which do not belong to a Capp are not indexed.
+func ManifestWorkCappIndexFunc(obj client.Object) []string {
+	labels := obj.GetLabels()
+	name, namespace := labels[utils.CappNameLabelKey], labels[utils.CappNamespaceLabelKey]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []string{generateCappIndexValue(namespace, name)}
+}
+
+// ListCappManifestWorks returns the ManifestWorks of the Capp across all managed cluster namespaces,
+// using the CappIndexKey index.
+func ListCappManifestWorks(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp) ([]workv1.ManifestWork, error) {
+	mwList := workv1.ManifestWorkList{}
+	if err := k8sClient.List(ctx, &mwList, client.MatchingFields{CappIndexKey: generateCappIndexValue(capp.Namespace, capp.Name)}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks of Capp: %v", err.Error())
+	}
+	return mwList.Items, nil
+}
+
+// generateCappIndexValue returns the value of the CappIndexKey index for the Capp with the specified namespace and name.
+func generateCappIndexValue(namespace, name string) string {
+	return namespace + "/" + name
+}
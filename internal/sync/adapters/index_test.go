@@ -0,0 +1,51 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListCappManifestWorks(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, workv1.AddToScheme(scheme))
+
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace", UID: "test-uid"}}
+	otherCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "other-capp", Namespace: "test-namespace", UID: "other-uid"}}
+
+	var objects []workv1.ManifestWork
+	for _, cluster := range []string{"cluster1", "cluster2"} {
+		mws, err := GenerateManifestWorks(capp, cluster, [][]workv1.Manifest{{}}, nil)
+		assert.NoError(t, err)
+		objects = append(objects, *mws[0])
+	}
+	otherMWs, err := GenerateManifestWorks(otherCapp, "cluster1", [][]workv1.Manifest{{}}, nil)
+	assert.NoError(t, err)
+	objects = append(objects, *otherMWs[0])
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithIndex(&workv1.ManifestWork{}, CappIndexKey, ManifestWorkCappIndexFunc)
+	for i := range objects {
+		builder = builder.WithObjects(&objects[i])
+	}
+	fakeClient := builder.Build()
+
+	// Assert that the ManifestWorks of the Capp are listed across all managed cluster namespaces
+	mws, err := ListCappManifestWorks(ctx, fakeClient, capp)
+	assert.NoError(t, err)
+	assert.Len(t, mws, 2)
+	for _, mw := range mws {
+		assert.Equal(t, "test-capp", mw.Labels[utils.CappNameLabelKey])
+		assert.Equal(t, "test-namespace", mw.Labels[utils.CappNamespaceLabelKey])
+		assert.Equal(t, "test-uid", mw.Labels[utils.CappUIDLabelKey])
+		assert.Equal(t, "test-capp", mw.Annotations[utils.CappNameLabelKey])
+		assert.NotEmpty(t, mw.Annotations[ManifestsHashAnnotationKey])
+	}
+}
@@ -2,12 +2,21 @@ package adapters
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
 	"github.com/dana-team/rcs-ocm-deployer/internal/utils/events"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	workv1 "open-cluster-management.io/api/work/v1"
@@ -16,8 +25,17 @@ import (
 
 const (
 	namespaceManifestWorkPrefix = "mw-create-"
-	cappNameKey                 = "rcs.dana.io/capp-name"
-	cappNamespaceKey            = "rcs.dana.io/capp-namespace"
+
+	// FieldManager is the field manager used when applying ManifestWorks on the hub cluster.
+	FieldManager = "rcs-ocm-deployer"
+
+	// maxMWNameLength is the maximum length of a ManifestWork name. The work agent names the AppliedManifestWork
+	// of a ManifestWork after it, prefixed with a 64 characters hash of the hub, within the 253 characters limit.
+	maxMWNameLength = 253 - 65
+	// mwPartSuffixLength is the length reserved in a ManifestWork name for the suffix of its part index.
+	mwPartSuffixLength = 4
+	// mwNameHashLength is the length of the hash of the Capp namespace and name in a ManifestWork name.
+	mwNameHashLength = 10
 )
 
 // GenerateManifestWorkGeneric generates a new Kubernetes manifest work object
@@ -25,7 +43,10 @@ const (
 // of machine configuration options as well.
 func GenerateManifestWorkGeneric(name string, namespace string, manifests []workv1.Manifest, machineConfigOptions ...workv1.ManifestConfigOption) *workv1.ManifestWork {
 	return &workv1.ManifestWork{
-		TypeMeta: metav1.TypeMeta{},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ManifestWork",
+			APIVersion: workv1.GroupVersion.String(),
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -39,30 +60,200 @@ func GenerateManifestWorkGeneric(name string, namespace string, manifests []work
 	}
 }
 
-// GenerateMWName returns a manifestWork name combining NamespaceManifestWorkPrefix, capp namespace and name.
+// GenerateMWName returns a manifestWork name combining NamespaceManifestWorkPrefix, capp namespace and name,
+// and a hash of the capp namespace and name. The namespace and name are truncated if the name would otherwise
+// be too long, and the hash keeps the name unique in that case, as well as when different namespaces and names
+// combine into the same string.
 func GenerateMWName(capp cappv1alpha1.Capp) string {
-	return namespaceManifestWorkPrefix + capp.Namespace + "-" + capp.Name
+	sum := sha256.Sum256([]byte(capp.Namespace + "/" + capp.Name))
+	hash := hex.EncodeToString(sum[:])[:mwNameHashLength]
+
+	readableName := capp.Namespace + "-" + capp.Name
+	maxReadableLength := maxMWNameLength - mwPartSuffixLength - len(namespaceManifestWorkPrefix) - len(hash) - 1
+	if len(readableName) > maxReadableLength {
+		readableName = strings.TrimRight(readableName[:maxReadableLength], "-")
+	}
+	return namespaceManifestWorkPrefix + readableName + "-" + hash
+}
+
+// GenerateMWPartName returns the name of the ManifestWork holding the specified part of the manifests of the Capp.
+// The first part is named after GenerateMWName, and every other part is suffixed with its 1-based index.
+func GenerateMWPartName(capp cappv1alpha1.Capp, part int) string {
+	if part == 0 {
+		return GenerateMWName(capp)
+	}
+	return GenerateMWName(capp) + "-" + strconv.Itoa(part+1)
+}
+
+// getMWPartIndex returns the 0-based index of the part held by the ManifestWork with the specified name,
+// and whether the name belongs to one of the ManifestWorks of the Capp.
+func getMWPartIndex(capp cappv1alpha1.Capp, name string) (int, bool) {
+	return getPartIndex(GenerateMWName(capp), name)
+}
+
+// getPartIndex returns the 0-based index of the part held by the ManifestWork with the specified name,
+// and whether the name is the specified base name of the ManifestWorks or one of its suffixed parts.
+func getPartIndex(baseName string, name string) (int, bool) {
+	if name == baseName {
+		return 0, true
+	}
+	suffix, found := strings.CutPrefix(name, baseName+"-")
+	if !found {
+		return 0, false
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index < 2 || strconv.Itoa(index) != suffix {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+// GenerateManifestWorks generates the ManifestWorks deploying the specified parts of the manifests of the Capp
+// on the managed cluster. Each ManifestWork holds the update strategies of its own manifests and the hash of its content.
+func GenerateManifestWorks(capp cappv1alpha1.Capp, managedClusterName string, parts [][]workv1.Manifest, strategies []rcsv1alpha1.ResourceUpdateStrategy) ([]*workv1.ManifestWork, error) {
+	var mws []*workv1.ManifestWork
+	for i, manifests := range parts {
+		mw := GenerateManifestWorkGeneric(GenerateMWPartName(capp, i), managedClusterName, manifests, GenerateManifestConfigs(manifests, strategies)...)
+		SetManifestWorkCappAnnotations(mw, capp)
+		SetManifestWorkCappLabels(mw, capp)
+		if err := SetManifestWorkHashAnnotation(mw); err != nil {
+			return nil, fmt.Errorf("failed to hash ManifestWork: %v", err.Error())
+		}
+		mws = append(mws, mw)
+	}
+	return mws, nil
+}
+
+// ListManifestWorkParts returns the ManifestWorks of the Capp in the specified managed cluster namespace,
+// ordered by the index of the part they hold. The ManifestWorks are looked up by the labels of the Capp,
+// so that ManifestWorks of other Capps whose name looks like a part of the Capp are never treated as its parts.
+func ListManifestWorkParts(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp, managedClusterName string) ([]workv1.ManifestWork, error) {
+	mwList := workv1.ManifestWorkList{}
+	cappLabels := client.MatchingLabels{utils.CappNameLabelKey: capp.Name, utils.CappNamespaceLabelKey: capp.Namespace}
+	if err := k8sClient.List(ctx, &mwList, client.InNamespace(managedClusterName), cappLabels); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks: %v", err.Error())
+	}
+
+	var mws []workv1.ManifestWork
+	for _, mw := range mwList.Items {
+		if _, ok := getMWPartIndex(capp, mw.Name); ok {
+			mws = append(mws, mw)
+		}
+	}
+	sort.Slice(mws, func(i, j int) bool {
+		first, _ := getMWPartIndex(capp, mws[i].Name)
+		second, _ := getMWPartIndex(capp, mws[j].Name)
+		return first < second
+	})
+	return mws, nil
 }
 
-// CreateManifestWork uses the Kubernetes client to create a ManifestWork resource
-// from the specified capp, cluster name, manifests, and logs the process.
-func CreateManifestWork(capp cappv1alpha1.Capp, managedClusterName string, logger logr.Logger, client client.Client, ctx context.Context, e record.EventRecorder, manifests []workv1.Manifest) error {
-	mwName := GenerateMWName(capp)
-	mw := GenerateManifestWorkGeneric(GenerateMWName(capp), managedClusterName, manifests, workv1.ManifestConfigOption{})
-	SetManifestWorkCappAnnotations(*mw, capp)
-	if err := client.Create(ctx, mw); err != nil {
+// DeleteSurplusManifestWorkParts deletes the ManifestWorks of the Capp which hold a part whose index is
+// not lower than the specified number of parts. It is used when the manifests of the Capp shrink into fewer parts.
+func DeleteSurplusManifestWorkParts(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp, managedClusterName string, parts int, logger logr.Logger) error {
+	mws, err := ListManifestWorkParts(ctx, k8sClient, capp, managedClusterName)
+	if err != nil {
+		return err
+	}
+	for _, mw := range mws {
+		if index, _ := getMWPartIndex(capp, mw.Name); index < parts || mw.DeletionTimestamp != nil {
+			continue
+		}
+		logger.Info(fmt.Sprintf("Deleting surplus ManifestWork %q of Capp %q", mw.Name, capp.Name))
+		if err := k8sClient.Delete(ctx, &mw); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete surplus ManifestWork: %v", err.Error())
+		}
+	}
+	return nil
+}
+
+// IsManifestWorkApplied returns whether the workload of the ManifestWork has been applied on the managed cluster.
+func IsManifestWorkApplied(mw workv1.ManifestWork) bool {
+	return meta.IsStatusConditionTrue(mw.Status.Conditions, workv1.WorkApplied)
+}
+
+// CreateManifestWork uses the Kubernetes client to create the specified ManifestWork resource
+// for the specified capp, and logs the process.
+func CreateManifestWork(capp cappv1alpha1.Capp, mw *workv1.ManifestWork, logger logr.Logger, client client.Client, ctx context.Context, e record.EventRecorder) error {
+	if err := ApplyManifestWork(ctx, client, mw); err != nil {
 		e.Event(&capp, corev1.EventTypeWarning, events.EventCappManifestWorkCreationFailed, err.Error())
 		return fmt.Errorf("failed to create ManifestWork: %v", err.Error())
 	}
-	logger.Info(fmt.Sprintf("Created ManifestWork %q for Capp %q", mwName, capp.Name))
-	e.Event(&capp, corev1.EventTypeNormal, events.EventCappManifestWorkCreated, fmt.Sprintf("Created ManifestWork %q for Capp %q", mwName, capp.Name))
+	logger.Info(fmt.Sprintf("Created ManifestWork %q for Capp %q", mw.Name, capp.Name))
+	e.Event(&capp, corev1.EventTypeNormal, events.EventCappManifestWorkCreated, fmt.Sprintf("Created ManifestWork %q for Capp %q", mw.Name, capp.Name))
 	return nil
 }
 
 // SetManifestWorkCappAnnotations sets the annotations of the specified manifest
 // work object with the name and namespace of the specified Capp object.
-func SetManifestWorkCappAnnotations(mw workv1.ManifestWork, capp cappv1alpha1.Capp) {
-	mw.ObjectMeta.Annotations = make(map[string]string)
-	mw.Annotations[cappNameKey] = capp.Name
-	mw.Annotations[cappNamespaceKey] = capp.Namespace
+func SetManifestWorkCappAnnotations(mw *workv1.ManifestWork, capp cappv1alpha1.Capp) {
+	if mw.Annotations == nil {
+		mw.Annotations = make(map[string]string)
+	}
+	mw.Annotations[utils.CappNameLabelKey] = capp.Name
+	mw.Annotations[utils.CappNamespaceLabelKey] = capp.Namespace
+}
+
+// SetManifestWorkCappLabels labels the specified manifest work object as managed by rcs,
+// and with the name, namespace and UID of the specified Capp object, so that it can be looked up by them.
+func SetManifestWorkCappLabels(mw *workv1.ManifestWork, capp cappv1alpha1.Capp) {
+	if mw.Labels == nil {
+		mw.Labels = make(map[string]string)
+	}
+	mw.Labels[utils.MangedByLableKey] = utils.MangedByLabelValue
+	mw.Labels[utils.CappNameLabelKey] = capp.Name
+	mw.Labels[utils.CappNamespaceLabelKey] = capp.Namespace
+	mw.Labels[utils.CappUIDLabelKey] = string(capp.UID)
+}
+
+// ApplyManifestWork creates or updates the specified ManifestWork using server-side apply.
+// The ManifestWork is applied with FieldManager as its field manager, and conflicts with other
+// field managers are resolved by forcing the ownership of the applied fields.
+func ApplyManifestWork(ctx context.Context, k8sClient client.Client, mw *workv1.ManifestWork) error {
+	return k8sClient.Patch(ctx, mw, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+}
+
+// GenerateManifestConfigs returns a ManifestConfigOption for every manifest whose group and resource
+// match one of the specified update strategies, so that the work agent updates it using that strategy.
+// Manifests which do not match any strategy are left out and use the default update strategy.
+func GenerateManifestConfigs(manifests []workv1.Manifest, strategies []rcsv1alpha1.ResourceUpdateStrategy) []workv1.ManifestConfigOption {
+	var manifestConfigs []workv1.ManifestConfigOption
+	for _, manifest := range manifests {
+		if manifest.Object == nil {
+			continue
+		}
+		obj, err := meta.Accessor(manifest.Object)
+		if err != nil {
+			continue
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(manifest.Object.GetObjectKind().GroupVersionKind())
+		for _, strategy := range strategies {
+			if strategy.Group != gvr.Group || strategy.Resource != gvr.Resource {
+				continue
+			}
+			manifestConfigs = append(manifestConfigs, workv1.ManifestConfigOption{
+				ResourceIdentifier: workv1.ResourceIdentifier{
+					Group:     gvr.Group,
+					Resource:  gvr.Resource,
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+				},
+				UpdateStrategy: generateUpdateStrategy(strategy),
+			})
+			break
+		}
+	}
+	return manifestConfigs
+}
+
+// generateUpdateStrategy converts a ResourceUpdateStrategy to the UpdateStrategy used by the work agent.
+func generateUpdateStrategy(strategy rcsv1alpha1.ResourceUpdateStrategy) *workv1.UpdateStrategy {
+	updateStrategy := &workv1.UpdateStrategy{Type: strategy.Type}
+	if strategy.Type == workv1.UpdateStrategyTypeServerSideApply {
+		updateStrategy.ServerSideApply = &workv1.ServerSideApplyConfig{
+			Force:        strategy.Force,
+			FieldManager: workv1.DefaultFieldManager,
+		}
+	}
+	return updateStrategy
 }
@@ -0,0 +1,75 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+func TestGenerateManifestConfigs(t *testing.T) {
+	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test-namespace"}}
+	configMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "test-namespace"}}
+	manifests := []workv1.Manifest{
+		builder.BuildNamespace("test-namespace"),
+		builder.BuildSecret(secret),
+		builder.BuildConfigMap(configMap),
+	}
+	strategies := []rcsv1alpha1.ResourceUpdateStrategy{
+		{Resource: "secrets", Type: workv1.UpdateStrategyTypeCreateOnly},
+		{Resource: "configmaps", Type: workv1.UpdateStrategyTypeServerSideApply, Force: true},
+	}
+
+	manifestConfigs := GenerateManifestConfigs(manifests, strategies)
+
+	// Assert that only the Secret and the ConfigMap got a manifest config
+	assert.Len(t, manifestConfigs, 2)
+
+	// Assert that the Secret is identified correctly and is only created
+	assert.Equal(t, workv1.ResourceIdentifier{Resource: "secrets", Name: "test-secret", Namespace: "test-namespace"}, manifestConfigs[0].ResourceIdentifier)
+	assert.Equal(t, workv1.UpdateStrategyTypeCreateOnly, manifestConfigs[0].UpdateStrategy.Type)
+	assert.Nil(t, manifestConfigs[0].UpdateStrategy.ServerSideApply)
+
+	// Assert that the ConfigMap is force applied using server-side apply
+	assert.Equal(t, "test-configmap", manifestConfigs[1].ResourceIdentifier.Name)
+	assert.Equal(t, workv1.UpdateStrategyTypeServerSideApply, manifestConfigs[1].UpdateStrategy.Type)
+	assert.True(t, manifestConfigs[1].UpdateStrategy.ServerSideApply.Force)
+}
+
+func TestGenerateManifestConfigsWithoutStrategies(t *testing.T) {
+	manifests := []workv1.Manifest{builder.BuildNamespace("test-namespace")}
+
+	// Assert that no manifest config is generated when no strategy is configured
+	assert.Empty(t, GenerateManifestConfigs(manifests, nil))
+}
+
+func TestGenerateMWName(t *testing.T) {
+	firstCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "a-b"}}
+	secondCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "b-c", Namespace: "a"}}
+
+	// Assert that the name is deterministic and does not collide for namespaces and names combining into the same string
+	assert.Equal(t, GenerateMWName(firstCapp), GenerateMWName(firstCapp))
+	assert.NotEqual(t, GenerateMWName(firstCapp), GenerateMWName(secondCapp))
+	assert.True(t, strings.HasPrefix(GenerateMWName(firstCapp), "mw-create-a-b-c-"))
+
+	// Assert that the names of all the parts of a Capp with the longest namespace and name a Capp can have,
+	// as both are DNS labels, stay within the limit
+	longCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("n", 63), Namespace: strings.Repeat("s", 63)}}
+	otherLongCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("n", 62), Namespace: strings.Repeat("s", 63)}}
+	assert.LessOrEqual(t, len(GenerateMWPartName(longCapp, 99)), maxMWNameLength)
+	assert.NotEqual(t, GenerateMWName(longCapp), GenerateMWName(otherLongCapp))
+
+	// Assert that the Capp labels of its ManifestWorks are valid label values
+	mw := workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: GenerateMWName(longCapp)}}
+	SetManifestWorkCappLabels(&mw, longCapp)
+	for key, value := range mw.Labels {
+		assert.Empty(t, validation.IsValidLabelValue(value), key)
+	}
+}
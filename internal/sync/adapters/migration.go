@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// generateLegacyMWName returns the name of the ManifestWork of the Capp under the legacy naming scheme,
+// which combined the Capp namespace and name without a hash.
+func generateLegacyMWName(capp cappv1alpha1.Capp) string {
+	return namespaceManifestWorkPrefix + capp.Namespace + "-" + capp.Name
+}
+
+// ListLegacyManifestWorks returns the ManifestWorks of the Capp in the specified managed cluster namespace
+// which were created under the legacy naming scheme. Since legacy names may collide, a ManifestWork is only
+// considered to belong to the Capp if it is not labelled with any Capp and does not deploy a different Capp.
+func ListLegacyManifestWorks(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp, managedClusterName string) ([]workv1.ManifestWork, error) {
+	mwList := workv1.ManifestWorkList{}
+	if err := k8sClient.List(ctx, &mwList, client.InNamespace(managedClusterName)); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks: %v", err.Error())
+	}
+
+	var mws []workv1.ManifestWork
+	for _, mw := range mwList.Items {
+		if _, ok := getPartIndex(generateLegacyMWName(capp), mw.Name); !ok {
+			continue
+		}
+		if _, labelled := mw.Labels[utils.CappNameLabelKey]; labelled {
+			continue
+		}
+		if deploysOtherCapp(mw, capp) {
+			continue
+		}
+		mws = append(mws, mw)
+	}
+	return mws, nil
+}
+
+// ReleaseLegacyManifestWorks deletes the specified legacy ManifestWorks while orphaning their resources on the
+// managed cluster. The resources are then adopted by the ManifestWorks of the current naming scheme, which deploy
+// the same resources, instead of being deleted and recreated.
+func ReleaseLegacyManifestWorks(ctx context.Context, k8sClient client.Client, mws []workv1.ManifestWork, logger logr.Logger) error {
+	for _, mw := range mws {
+		if mw.DeletionTimestamp != nil {
+			continue
+		}
+		if err := orphanManifestWork(ctx, k8sClient, mw, false); err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("Deleting legacy ManifestWork %q", mw.Name))
+		if err := k8sClient.Delete(ctx, &mw); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete legacy ManifestWork %q: %v", mw.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// deploysOtherCapp returns whether the ManifestWork deploys a Capp other than the specified Capp.
+func deploysOtherCapp(mw workv1.ManifestWork, capp cappv1alpha1.Capp) bool {
+	for _, manifest := range mw.Spec.Workload.Manifests {
+		obj := unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			continue
+		}
+		if obj.GetKind() == "Capp" && (obj.GetName() != capp.Name || obj.GetNamespace() != capp.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// orphanManifestWork sets the delete option of the ManifestWork to orphan its resources on the managed cluster
+// once it is deleted. If removeFinalizers is set, the finalizers of the ManifestWork are removed as well,
+// so that its deletion does not wait for the work agent.
+func orphanManifestWork(ctx context.Context, k8sClient client.Client, mw workv1.ManifestWork, removeFinalizers bool) error {
+	isOrphaned := mw.Spec.DeleteOption != nil && mw.Spec.DeleteOption.PropagationPolicy == workv1.DeletePropagationPolicyTypeOrphan
+	if isOrphaned && (!removeFinalizers || len(mw.Finalizers) == 0) {
+		return nil
+	}
+
+	patch := client.MergeFrom(mw.DeepCopy())
+	mw.Spec.DeleteOption = &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}
+	if removeFinalizers {
+		mw.Finalizers = nil
+	}
+	if err := k8sClient.Patch(ctx, &mw, patch); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to orphan resources of ManifestWork %q: %v", mw.Name, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newLegacyManifestWork(name string, manifests ...workv1.Manifest) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cluster"},
+		Spec:       workv1.ManifestWorkSpec{Workload: workv1.ManifestsTemplate{Manifests: manifests}},
+	}
+}
+
+func TestListLegacyManifestWorks(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, workv1.AddToScheme(scheme))
+
+	capp := cappv1alpha1.Capp{
+		TypeMeta:   metav1.TypeMeta{Kind: "Capp"},
+		ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "a-b"},
+	}
+	collidingCapp := cappv1alpha1.Capp{
+		TypeMeta:   metav1.TypeMeta{Kind: "Capp"},
+		ObjectMeta: metav1.ObjectMeta{Name: "b-c", Namespace: "a"},
+	}
+	labelledMW := newLegacyManifestWork("mw-create-a-b-c-2")
+	labelledMW.Labels = map[string]string{utils.CappNameLabelKey: "c"}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newLegacyManifestWork("mw-create-a-b-c", builder.BuildCapp(capp)),
+		newLegacyManifestWork("mw-create-a-b-c-3"),
+		newLegacyManifestWork("mw-create-a-b-c-4", builder.BuildCapp(collidingCapp)),
+		labelledMW,
+		newLegacyManifestWork("mw-create-a-b-other"),
+	).Build()
+
+	// Assert that only unlabelled ManifestWorks of the Capp under the legacy name are listed
+	mws, err := ListLegacyManifestWorks(ctx, fakeClient, capp, "cluster")
+	assert.NoError(t, err)
+	var names []string
+	for _, mw := range mws {
+		names = append(names, mw.Name)
+	}
+	assert.ElementsMatch(t, []string{"mw-create-a-b-c", "mw-create-a-b-c-3"}, names)
+
+	// Assert that released ManifestWorks orphan their resources and are deleted
+	assert.NoError(t, ReleaseLegacyManifestWorks(ctx, fakeClient, mws, logr.Discard()))
+	mws, err = ListLegacyManifestWorks(ctx, fakeClient, capp, "cluster")
+	assert.NoError(t, err)
+	assert.Empty(t, mws)
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "mw-create-a-b-c-4", Namespace: "cluster"}, &workv1.ManifestWork{}))
+}
@@ -0,0 +1,268 @@
+package adapters
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// DefaultRevisionHistoryLimit is the number of revisions kept for each Capp when no limit is configured.
+	DefaultRevisionHistoryLimit = 10
+
+	// revisionHashLength is the length of the hash of the revision data in the name of a revision.
+	revisionHashLength = 10
+
+	// maxRevisionManifestsSize is the maximal total size of the manifests stored in a revision, which keeps
+	// revisions well within the size limit of an object.
+	maxRevisionManifestsSize = 1024 * 1024
+)
+
+// RevisionData is the content of a revision of a Capp: the spec of the Capp, the manifests which were shipped for it
+// to the managed cluster except for Secrets, and a hash of all the shipped manifests without the data of Secrets.
+// If the manifests exceed maxRevisionManifestsSize, they are omitted and only their hash is stored.
+type RevisionData struct {
+	Spec             cappv1alpha1.CappSpec  `json:"spec"`
+	Manifests        []runtime.RawExtension `json:"manifests,omitempty"`
+	ManifestsOmitted bool                   `json:"manifestsOmitted,omitempty"`
+	ManifestsHash    string                 `json:"manifestsHash"`
+}
+
+// GetRevisionHistoryLimit returns the revision history limit of the RCS Config, defaulting to DefaultRevisionHistoryLimit.
+func GetRevisionHistoryLimit(config rcsv1alpha1.RCSConfig) int {
+	if config.Spec.RevisionHistoryLimit == nil {
+		return DefaultRevisionHistoryLimit
+	}
+	return int(*config.Spec.RevisionHistoryLimit)
+}
+
+// GenerateRevisionData returns the serialized revision data of the Capp and the manifests shipped for it,
+// along with its hash.
+func GenerateRevisionData(capp cappv1alpha1.Capp, manifests []workv1.Manifest) ([]byte, string, error) {
+	data := RevisionData{Spec: capp.Spec}
+	manifestsHash := sha256.New()
+	manifestsSize := 0
+	for _, manifest := range manifests {
+		raw, err := canonicalizeManifest(manifest)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to normalize manifest: %v", err.Error())
+		}
+		stripped, err := removeSecretData(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		manifestsHash.Write(stripped)
+		if isSecretManifest(raw) {
+			continue
+		}
+		manifestsSize += len(raw)
+		data.Manifests = append(data.Manifests, runtime.RawExtension{Raw: raw})
+	}
+	if manifestsSize > maxRevisionManifestsSize {
+		data.Manifests, data.ManifestsOmitted = nil, true
+	}
+	data.ManifestsHash = hex.EncodeToString(manifestsHash.Sum(nil))
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal revision data: %v", err.Error())
+	}
+	sum := sha256.Sum256(raw)
+	return raw, hex.EncodeToString(sum[:]), nil
+}
+
+// isSecretManifest returns whether the manifest is a Secret.
+func isSecretManifest(raw []byte) bool {
+	object := metav1.TypeMeta{}
+	return json.Unmarshal(raw, &object) == nil && object.Kind == "Secret"
+}
+
+// removeSecretData removes the data of the manifest if it is a Secret, so that it does not affect revisions.
+func removeSecretData(raw []byte) ([]byte, error) {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %v", err.Error())
+	}
+	if object["kind"] != "Secret" {
+		return raw, nil
+	}
+	delete(object, "data")
+	delete(object, "stringData")
+	return json.Marshal(object)
+}
+
+// ListRevisions returns the revisions of the Capp, ordered by their revision number.
+func ListRevisions(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp) ([]appsv1.ControllerRevision, error) {
+	revisionList := appsv1.ControllerRevisionList{}
+	if err := k8sClient.List(ctx, &revisionList, client.InNamespace(capp.Namespace), client.MatchingLabels{utils.CappNameLabelKey: capp.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %v", err.Error())
+	}
+
+	var revisions []appsv1.ControllerRevision
+	for _, revision := range revisionList.Items {
+		if metav1.IsControlledBy(&revision, &capp) {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// RecordRevision records the Capp and the hash of the manifests shipped for it as a ControllerRevision owned by the Capp,
+// unless the latest revision already holds the same data. If an older revision holds the same data, as happens
+// after a rollback, it becomes the latest revision. Revisions beyond the history limit are deleted, oldest first.
+func RecordRevision(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, capp cappv1alpha1.Capp, manifests []workv1.Manifest, limit int) error {
+	revisions, err := ListRevisions(ctx, k8sClient, capp)
+	if err != nil {
+		return err
+	}
+	if limit > 0 {
+		data, hash, err := GenerateRevisionData(capp, manifests)
+		if err != nil {
+			return err
+		}
+		if revisions, err = recordRevision(ctx, k8sClient, scheme, capp, revisions, data, hash); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(revisions)-limit; i++ {
+		if err := k8sClient.Delete(ctx, &revisions[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete revision %q: %v", revisions[i].Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// recordRevision creates or promotes the revision with the specified data and hash, and returns the revisions
+// of the Capp including it, ordered by their revision number.
+func recordRevision(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, capp cappv1alpha1.Capp, revisions []appsv1.ControllerRevision, data []byte, hash string) ([]appsv1.ControllerRevision, error) {
+	var nextRevision int64 = 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if latest.Labels[utils.RevisionHashLabelKey] == hash[:revisionHashLength] {
+			return revisions, nil
+		}
+		nextRevision = latest.Revision + 1
+	}
+
+	for i, revision := range revisions {
+		if revision.Labels[utils.RevisionHashLabelKey] != hash[:revisionHashLength] {
+			continue
+		}
+		revision.Revision = nextRevision
+		if err := k8sClient.Update(ctx, &revision); err != nil {
+			return nil, fmt.Errorf("failed to update revision %q: %v", revision.Name, err.Error())
+		}
+		return append(append(revisions[:i:i], revisions[i+1:]...), revision), nil
+	}
+
+	revision := appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      capp.Name + "-" + hash[:revisionHashLength],
+			Namespace: capp.Namespace,
+			Labels: map[string]string{
+				utils.MangedByLableKey:     utils.MangedByLabelValue,
+				utils.CappNameLabelKey:     capp.Name,
+				utils.RevisionHashLabelKey: hash[:revisionHashLength],
+			},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: nextRevision,
+	}
+	if err := controllerutil.SetControllerReference(&capp, &revision, scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner of revision: %v", err.Error())
+	}
+	if err := k8sClient.Create(ctx, &revision); err != nil {
+		return nil, fmt.Errorf("failed to create revision %q: %v", revision.Name, err.Error())
+	}
+	return append(revisions, revision), nil
+}
+
+// RollbackCapp restores the spec of the Capp from the revision with the specified revision number, along with
+// the ConfigMaps shipped with it, and removes the rollback annotation from the Capp. The manifests of the restored
+// Capp are then shipped to the managed cluster by the next sync. It returns whether the ConfigMaps were restored,
+// which is not the case for revisions whose manifests were too large to be stored.
+func RollbackCapp(ctx context.Context, k8sClient client.Client, capp cappv1alpha1.Capp, revisionNumber string) (bool, error) {
+	number, err := strconv.ParseInt(revisionNumber, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid revision %q: %v", revisionNumber, err.Error())
+	}
+	revisions, err := ListRevisions(ctx, k8sClient, capp)
+	if err != nil {
+		return false, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Revision != number {
+			continue
+		}
+		data := RevisionData{}
+		if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+			return false, fmt.Errorf("failed to unmarshal revision %q: %v", revision.Name, err.Error())
+		}
+		if err := restoreConfigMaps(ctx, k8sClient, data.Manifests); err != nil {
+			return false, err
+		}
+		capp.Spec = data.Spec
+		delete(capp.Annotations, utils.AnnotationKeyRollbackToRevision)
+		if err := k8sClient.Update(ctx, &capp); err != nil {
+			return false, fmt.Errorf("failed to roll back Capp: %v", err.Error())
+		}
+		return !data.ManifestsOmitted, nil
+	}
+	return false, fmt.Errorf("revision %d of Capp was not found", number)
+}
+
+// restoreConfigMaps restores the data of the ConfigMaps among the manifests of a revision on the hub,
+// creating the ConfigMaps which no longer exist, so that the next sync ships them as they were shipped then.
+func restoreConfigMaps(ctx context.Context, k8sClient client.Client, manifests []runtime.RawExtension) error {
+	for _, manifest := range manifests {
+		shipped := corev1.ConfigMap{}
+		if err := json.Unmarshal(manifest.Raw, &shipped); err != nil {
+			return fmt.Errorf("failed to unmarshal manifest: %v", err.Error())
+		}
+		if shipped.Kind != "ConfigMap" {
+			continue
+		}
+
+		configMap := corev1.ConfigMap{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: shipped.Name, Namespace: shipped.Namespace}, &configMap); err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get ConfigMap %q: %v", shipped.Name, err.Error())
+			}
+			configMap = corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: shipped.Name, Namespace: shipped.Namespace}, Data: shipped.Data}
+			if err := k8sClient.Create(ctx, &configMap); err != nil {
+				return fmt.Errorf("failed to restore ConfigMap %q: %v", shipped.Name, err.Error())
+			}
+			continue
+		}
+		if reflect.DeepEqual(configMap.Data, shipped.Data) {
+			continue
+		}
+		configMap.Data = shipped.Data
+		if err := k8sClient.Update(ctx, &configMap); err != nil {
+			return fmt.Errorf("failed to restore ConfigMap %q: %v", shipped.Name, err.Error())
+		}
+	}
+	return nil
+}
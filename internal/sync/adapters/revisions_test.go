@@ -0,0 +1,129 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGenerateRevisionData(t *testing.T) {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace"}}
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test-namespace"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	configMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "test-namespace"}, Data: map[string]string{"key": "value"}}
+
+	raw, hash, err := GenerateRevisionData(capp, []workv1.Manifest{builder.BuildSecret(secret), builder.BuildConfigMap(configMap)})
+	assert.NoError(t, err)
+
+	// Assert that the manifests are stored in revisions, except for Secrets
+	data := RevisionData{}
+	assert.NoError(t, json.Unmarshal(raw, &data))
+	assert.NotEmpty(t, data.ManifestsHash)
+	assert.Len(t, data.Manifests, 1)
+	assert.Contains(t, string(data.Manifests[0].Raw), "test-configmap")
+	assert.NotContains(t, string(raw), "test-secret")
+
+	// Assert that the data of Secrets does not affect revisions, while the other changes of the manifests do
+	secret.Data = map[string][]byte{"password": []byte("changed")}
+	_, otherHash, err := GenerateRevisionData(capp, []workv1.Manifest{builder.BuildSecret(secret), builder.BuildConfigMap(configMap)})
+	assert.NoError(t, err)
+	assert.Equal(t, hash, otherHash)
+	secret.Type = corev1.SecretTypeOpaque
+	_, otherHash, err = GenerateRevisionData(capp, []workv1.Manifest{builder.BuildSecret(secret), builder.BuildConfigMap(configMap)})
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	// Assert that manifests too large to be stored are omitted, keeping their hash
+	configMap.Data = map[string]string{"key": strings.Repeat("v", maxRevisionManifestsSize)}
+	raw, _, err = GenerateRevisionData(capp, []workv1.Manifest{builder.BuildConfigMap(configMap)})
+	assert.NoError(t, err)
+	data = RevisionData{}
+	assert.NoError(t, json.Unmarshal(raw, &data))
+	assert.Empty(t, data.Manifests)
+	assert.True(t, data.ManifestsOmitted)
+	assert.NotEmpty(t, data.ManifestsHash)
+}
+
+func TestRecordRevisionAndRollback(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	capp := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace", UID: "test-uid"}}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-configmap", Namespace: "test-namespace"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(capp, configMap).Build()
+	getConfigMap := func() corev1.ConfigMap {
+		configMap := corev1.ConfigMap{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-configmap", Namespace: "test-namespace"}, &configMap))
+		return configMap
+	}
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+	record := func(site string) {
+		current := getCapp()
+		current.Spec.Site = site
+		assert.NoError(t, fakeClient.Update(ctx, &current))
+		configMap := getConfigMap()
+		configMap.Data = map[string]string{"site": site}
+		assert.NoError(t, fakeClient.Update(ctx, &configMap))
+		assert.NoError(t, RecordRevision(ctx, fakeClient, scheme, getCapp(), []workv1.Manifest{builder.BuildConfigMap(configMap)}, 2))
+	}
+
+	// Assert that a revision is recorded for every change, up to the limit
+	record("cluster1")
+	record("cluster1")
+	record("cluster2")
+	record("cluster3")
+	revisions, err := ListRevisions(ctx, fakeClient, getCapp())
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, int64(2), revisions[0].Revision)
+	assert.Equal(t, int64(3), revisions[1].Revision)
+
+	// Assert that rolling back restores the spec and the ConfigMaps of the revision and removes the annotation
+	current := getCapp()
+	current.Annotations = map[string]string{utils.AnnotationKeyRollbackToRevision: "2"}
+	assert.NoError(t, fakeClient.Update(ctx, &current))
+	restored, err := RollbackCapp(ctx, fakeClient, getCapp(), "2")
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, "cluster2", getCapp().Spec.Site)
+	assert.Equal(t, map[string]string{"site": "cluster2"}, getConfigMap().Data)
+	assert.NotContains(t, getCapp().Annotations, utils.AnnotationKeyRollbackToRevision)
+
+	// Assert that ConfigMaps deleted from the hub are created again on rollback
+	assert.NoError(t, fakeClient.Delete(ctx, configMap))
+	restored, err = RollbackCapp(ctx, fakeClient, getCapp(), "3")
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, map[string]string{"site": "cluster3"}, getConfigMap().Data)
+
+	// Assert that the rolled back revision becomes the latest revision
+	assert.NoError(t, RecordRevision(ctx, fakeClient, scheme, getCapp(), nil, 2))
+	revisions, err = ListRevisions(ctx, fakeClient, getCapp())
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, int64(4), revisions[1].Revision)
+	_, err = RollbackCapp(ctx, fakeClient, getCapp(), "1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+// DefaultManifestWorkSizeLimit is the default maximum size in bytes of the serialized manifests of a single ManifestWork.
+const DefaultManifestWorkSizeLimit int64 = 500 * 1024
+
+// manifestKindOrder defines the order in which manifests of each kind are distributed across ManifestWorks,
+// so that resources come after the resources they depend on. Kinds which are not listed come before the Capp.
+var manifestKindOrder = map[string]int{
+	"Namespace":   0,
+	"Role":        1,
+	"RoleBinding": 1,
+	"ConfigMap":   2,
+	"Secret":      2,
+	"Capp":        4,
+}
+
+// defaultManifestKindOrder is the order of manifests of kinds which are not listed in manifestKindOrder.
+const defaultManifestKindOrder = 3
+
+// GetManifestWorkSizeLimit returns the maximum size in bytes of the serialized manifests of a single ManifestWork
+// according to the RCS Config, or DefaultManifestWorkSizeLimit if it is not set.
+func GetManifestWorkSizeLimit(config rcsv1alpha1.RCSConfig) int64 {
+	if config.Spec.ManifestWorkSizeLimit == nil || config.Spec.ManifestWorkSizeLimit.Value() <= 0 {
+		return DefaultManifestWorkSizeLimit
+	}
+	return config.Spec.ManifestWorkSizeLimit.Value()
+}
+
+// SplitManifests distributes the manifests across parts whose serialized size does not exceed the size limit.
+// Manifests are first ordered so that the Namespace comes first and the Capp comes last, and parts are then
+// filled in that order. Creating the parts one after the other thus creates every resource after the resources
+// it depends on. A manifest which exceeds the size limit by itself is put in a part of its own.
+func SplitManifests(manifests []workv1.Manifest, sizeLimit int64) ([][]workv1.Manifest, error) {
+	ordered := make([]workv1.Manifest, len(manifests))
+	copy(ordered, manifests)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return getManifestKindOrder(ordered[i]) < getManifestKindOrder(ordered[j])
+	})
+
+	var parts [][]workv1.Manifest
+	var part []workv1.Manifest
+	var partSize int64
+	for _, manifest := range ordered {
+		size, err := getManifestSize(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute manifest size: %v", err.Error())
+		}
+		if len(part) > 0 && partSize+size > sizeLimit {
+			parts = append(parts, part)
+			part, partSize = nil, 0
+		}
+		part = append(part, manifest)
+		partSize += size
+	}
+	if len(part) > 0 || len(parts) == 0 {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// getManifestKindOrder returns the order in which a manifest is distributed according to its kind.
+func getManifestKindOrder(manifest workv1.Manifest) int {
+	if manifest.Object == nil {
+		return defaultManifestKindOrder
+	}
+	if order, ok := manifestKindOrder[manifest.Object.GetObjectKind().GroupVersionKind().Kind]; ok {
+		return order
+	}
+	return defaultManifestKindOrder
+}
+
+// getManifestSize returns the size in bytes of the serialized manifest.
+func getManifestSize(manifest workv1.Manifest) (int64, error) {
+	if manifest.Raw != nil {
+		return int64(len(manifest.Raw)), nil
+	}
+	raw, err := json.Marshal(manifest.Object)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(raw)), nil
+}
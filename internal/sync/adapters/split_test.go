@@ -0,0 +1,93 @@
+package adapters
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSplitManifests(t *testing.T) {
+	capp := cappv1alpha1.Capp{
+		TypeMeta:   metav1.TypeMeta{Kind: "Capp"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace"},
+	}
+	largeData := map[string]string{"key": strings.Repeat("a", 1024)}
+	firstConfigMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "test-namespace"}, Data: largeData}
+	secondConfigMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "test-namespace"}, Data: largeData}
+	manifests := []workv1.Manifest{
+		builder.BuildCapp(capp),
+		builder.BuildNamespace("test-namespace"),
+		builder.BuildConfigMap(firstConfigMap),
+		builder.BuildConfigMap(secondConfigMap),
+	}
+
+	// Assert that all the manifests are kept in a single part when they do not exceed the limit
+	parts, err := SplitManifests(manifests, DefaultManifestWorkSizeLimit)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 1)
+	assert.Len(t, parts[0], 4)
+
+	// Assert that the manifests are split when they exceed the limit
+	parts, err = SplitManifests(manifests, 1500)
+	assert.NoError(t, err)
+	assert.Greater(t, len(parts), 1)
+
+	// Assert that the Namespace comes first and the Capp comes last
+	assert.Equal(t, "Namespace", parts[0][0].Object.GetObjectKind().GroupVersionKind().Kind)
+	lastPart := parts[len(parts)-1]
+	assert.Equal(t, "Capp", lastPart[len(lastPart)-1].Object.GetObjectKind().GroupVersionKind().Kind)
+}
+
+func TestGetMWPartIndex(t *testing.T) {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace"}}
+
+	for part := 0; part < 3; part++ {
+		index, ok := getMWPartIndex(capp, GenerateMWPartName(capp, part))
+		assert.True(t, ok)
+		assert.Equal(t, part, index)
+	}
+
+	// Assert that names which do not belong to a part of the Capp are not matched
+	for _, name := range []string{GenerateMWName(capp) + "-1", GenerateMWName(capp) + "-02", GenerateMWName(capp) + "-x", "other"} {
+		_, ok := getMWPartIndex(capp, name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestListManifestWorkPartsOfOtherCapps(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, workv1.AddToScheme(scheme))
+
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns"}}
+	otherCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "foo-2", Namespace: "ns"}}
+
+	mws, err := GenerateManifestWorks(capp, "cluster1", [][]workv1.Manifest{{}}, nil)
+	assert.NoError(t, err)
+	otherMWs, err := GenerateManifestWorks(otherCapp, "cluster1", [][]workv1.Manifest{{}}, nil)
+	assert.NoError(t, err)
+	// The ManifestWork of the other Capp is named like the second part of the Capp, as legacy names could be
+	otherMWs[0].Name = GenerateMWPartName(capp, 1)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mws[0], otherMWs[0]).Build()
+
+	// Assert that only the ManifestWorks labeled with the Capp are its parts
+	parts, err := ListManifestWorkParts(ctx, fakeClient, capp, "cluster1")
+	assert.NoError(t, err)
+	assert.Len(t, parts, 1)
+	assert.Equal(t, GenerateMWName(capp), parts[0].Name)
+
+	// Assert that the ManifestWork of the other Capp is not deleted as a surplus part
+	assert.NoError(t, DeleteSurplusManifestWorkParts(ctx, fakeClient, capp, "cluster1", 1, logr.Discard()))
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(otherMWs[0]), &workv1.ManifestWork{}))
+}
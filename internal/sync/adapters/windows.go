@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"fmt"
+	"time"
+	// Embed the time zone database so that the time zones of the windows can be loaded in minimal images
+	_ "time/tzdata"
+
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils/cron"
+	"github.com/go-logr/logr"
+	"k8s.io/utils/strings/slices"
+)
+
+// UpdateDeferral describes why the updates of the ManifestWorks of a Capp are deferred.
+type UpdateDeferral struct {
+	Reason  string
+	Message string
+}
+
+// GetUpdateDeferral returns why the updates of the ManifestWorks of Capps in the specified namespace are deferred
+// at the specified time, or nil if they are not. Updates are deferred during the deploy freezes which apply to the
+// namespace, and outside the maintenance windows which apply to it, if any does. Windows and freezes with an invalid
+// schedule or time zone are logged and ignored, so that they do not block the sync of every Capp they apply to.
+func GetUpdateDeferral(config rcsv1alpha1.RCSConfig, namespace string, now time.Time, logger logr.Logger) *UpdateDeferral {
+	for _, freeze := range config.Spec.DeployFreezes {
+		if !appliesToNamespace(freeze.Namespaces, namespace) {
+			continue
+		}
+		active, err := isTimeWindowActive(freeze.TimeWindow, now)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid deploy freeze", "name", freeze.Name)
+			continue
+		}
+		if active {
+			return &UpdateDeferral{
+				Reason:  ReasonDeployFreeze,
+				Message: fmt.Sprintf("Updates are deferred during deploy freeze %q", freeze.Name),
+			}
+		}
+	}
+
+	hasWindows := false
+	for _, window := range config.Spec.MaintenanceWindows {
+		if !appliesToNamespace(window.Namespaces, namespace) {
+			continue
+		}
+		active, err := isTimeWindowActive(window.TimeWindow, now)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid maintenance window", "schedule", window.Schedule)
+			continue
+		}
+		hasWindows = true
+		if active {
+			return nil
+		}
+	}
+	if hasWindows {
+		return &UpdateDeferral{
+			Reason:  ReasonOutsideMaintenanceWindow,
+			Message: "Updates are deferred until the next maintenance window",
+		}
+	}
+	return nil
+}
+
+// appliesToNamespace returns whether a window with the specified namespaces applies to the namespace.
+func appliesToNamespace(namespaces []string, namespace string) bool {
+	return len(namespaces) == 0 || slices.Contains(namespaces, namespace)
+}
+
+// isTimeWindowActive returns whether the window is active at the specified time, in the time zone of the window.
+func isTimeWindowActive(window rcsv1alpha1.TimeWindow, now time.Time) (bool, error) {
+	schedule, err := cron.Parse(window.Schedule)
+	if err != nil {
+		return false, err
+	}
+	location := time.UTC
+	if window.TimeZone != "" {
+		if location, err = time.LoadLocation(window.TimeZone); err != nil {
+			return false, fmt.Errorf("invalid time zone %q: %v", window.TimeZone, err.Error())
+		}
+	}
+	return schedule.IsActive(now.In(location), window.Duration.Duration), nil
+}
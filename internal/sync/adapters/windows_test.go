@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetUpdateDeferral(t *testing.T) {
+	config := rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{
+		MaintenanceWindows: []rcsv1alpha1.MaintenanceWindow{{
+			TimeWindow: rcsv1alpha1.TimeWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}, TimeZone: "Asia/Jerusalem"},
+			Namespaces: []string{"production"},
+		}},
+		DeployFreezes: []rcsv1alpha1.DeployFreeze{{
+			Name:       "new-year",
+			TimeWindow: rcsv1alpha1.TimeWindow{Schedule: "0 0 31 12 *", Duration: metav1.Duration{Duration: 48 * time.Hour}},
+		}},
+	}}
+	// 22:30 in Asia/Jerusalem
+	insideWindow := time.Date(2024, 6, 3, 19, 30, 0, 0, time.UTC)
+	outsideWindow := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	duringFreeze := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	// Assert that updates are deferred outside the maintenance windows of the namespace
+	deferral := GetUpdateDeferral(config, "production", outsideWindow, logr.Discard())
+	assert.Equal(t, ReasonOutsideMaintenanceWindow, deferral.Reason)
+
+	deferral = GetUpdateDeferral(config, "production", insideWindow, logr.Discard())
+	assert.Nil(t, deferral)
+
+	deferral = GetUpdateDeferral(config, "development", outsideWindow, logr.Discard())
+	assert.Nil(t, deferral)
+
+	// Assert that updates are deferred during global deploy freezes
+	deferral = GetUpdateDeferral(config, "development", duringFreeze, logr.Discard())
+	assert.Equal(t, ReasonDeployFreeze, deferral.Reason)
+	assert.Contains(t, deferral.Message, "new-year")
+
+	// Assert that invalid windows and freezes are ignored rather than blocking the sync
+	config.Spec.DeployFreezes[0].Schedule = "invalid"
+	assert.Nil(t, GetUpdateDeferral(config, "development", duringFreeze, logr.Discard()))
+	config.Spec.MaintenanceWindows[0].TimeZone = "Invalid/Zone"
+	assert.Nil(t, GetUpdateDeferral(config, "production", outsideWindow, logr.Discard()))
+}
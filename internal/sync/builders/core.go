@@ -33,12 +33,53 @@ func BuildCapp(capp cappv1alpha1.Capp) workv1.Manifest {
 
 // BuildNamespace generates a corev1.Namespace object with the specified name.
 func BuildNamespace(name string) workv1.Manifest {
+	return BuildNamespaceWithMetadata(name, nil, nil)
+}
+
+// BuildNamespaceWithMetadata generates a corev1.Namespace object with the specified name, labels and annotations.
+// The managed-by label is always set on the namespace.
+func BuildNamespaceWithMetadata(name string, labels map[string]string, annotations map[string]string) workv1.Manifest {
+	namespaceLabels := map[string]string{}
+	for key, value := range labels {
+		namespaceLabels[key] = value
+	}
+	namespaceLabels[utils.MangedByLableKey] = utils.MangedByLabelValue
+
 	namespace := corev1.Namespace{
 		TypeMeta: metav1.TypeMeta{Kind: "Namespace", APIVersion: corev1.SchemeGroupVersion.Version},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   name,
-			Labels: map[string]string{utils.MangedByLableKey: utils.MangedByLabelValue},
+			Name:        name,
+			Labels:      namespaceLabels,
+			Annotations: annotations,
 		},
 	}
 	return workv1.Manifest{RawExtension: runtime.RawExtension{Object: &namespace}}
 }
+
+// BuildResourceQuota generates a manifest that contains a ResourceQuota with the spec of the specified ResourceQuota.
+func BuildResourceQuota(quota corev1.ResourceQuota) workv1.Manifest {
+	quotaManifest := &corev1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{Kind: "ResourceQuota", APIVersion: corev1.SchemeGroupVersion.Version},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      quota.Name,
+			Namespace: quota.Namespace,
+			Labels:    map[string]string{utils.MangedByLableKey: utils.MangedByLabelValue},
+		},
+		Spec: quota.Spec,
+	}
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Object: quotaManifest}}
+}
+
+// BuildLimitRange generates a manifest that contains a LimitRange with the spec of the specified LimitRange.
+func BuildLimitRange(limitRange corev1.LimitRange) workv1.Manifest {
+	limitRangeManifest := &corev1.LimitRange{
+		TypeMeta: metav1.TypeMeta{Kind: "LimitRange", APIVersion: corev1.SchemeGroupVersion.Version},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      limitRange.Name,
+			Namespace: limitRange.Namespace,
+			Labels:    map[string]string{utils.MangedByLableKey: utils.MangedByLabelValue},
+		},
+		Spec: limitRange.Spec,
+	}
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Object: limitRangeManifest}}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ManifestWorkPredicateFuncs filters the events of ManifestWorks managed by rcs which may have drifted
+// from their Capp, that is, deletions and changes of their spec, labels or annotations.
+var ManifestWorkPredicateFuncs = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if !isManagedManifestWork(e.ObjectNew) {
+			return false
+		}
+		return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+			!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+			!reflect.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+	},
+	CreateFunc: func(e event.CreateEvent) bool {
+		return false
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return isManagedManifestWork(e.Object)
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// isManagedManifestWork returns whether the ManifestWork is managed by rcs and belongs to a Capp.
+func isManagedManifestWork(obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels[utils.MangedByLableKey] == utils.MangedByLabelValue &&
+		labels[utils.CappNameLabelKey] != "" && labels[utils.CappNamespaceLabelKey] != ""
+}
+
+// getManifestWorkCapp returns the namespaced name of the Capp the ManifestWork belongs to.
+func getManifestWorkCapp(obj client.Object) types.NamespacedName {
+	labels := obj.GetLabels()
+	return types.NamespacedName{Name: labels[utils.CappNameLabelKey], Namespace: labels[utils.CappNamespaceLabelKey]}
+}
+
+// manifestWorkHandler enqueues the Capp of a ManifestWork on its events, and records deleted ManifestWorks
+// so that the sync controller can tell when it recreates a ManifestWork deleted out-of-band.
+func (r *SyncReconciler) manifestWorkHandler() handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			q.Add(reconcile.Request{NamespacedName: getManifestWorkCapp(e.ObjectNew)})
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			cappName := getManifestWorkCapp(e.Object)
+			r.deletedManifestWorks.add(cappName, client.ObjectKeyFromObject(e.Object))
+			q.Add(reconcile.Request{NamespacedName: cappName})
+		},
+	}
+}
+
+// deletedManifestWorks records the ManifestWorks deleted since the last sync of each Capp.
+type deletedManifestWorks struct {
+	mu    sync.Mutex
+	works map[types.NamespacedName]sets.Set[types.NamespacedName]
+}
+
+// add records the deletion of the specified ManifestWork of the Capp.
+func (d *deletedManifestWorks) add(capp types.NamespacedName, mw types.NamespacedName) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.works == nil {
+		d.works = make(map[types.NamespacedName]sets.Set[types.NamespacedName])
+	}
+	if d.works[capp] == nil {
+		d.works[capp] = sets.New[types.NamespacedName]()
+	}
+	d.works[capp].Insert(mw)
+}
+
+// pop returns the ManifestWorks of the Capp deleted since the last call, and forgets them.
+func (d *deletedManifestWorks) pop(capp types.NamespacedName) sets.Set[types.NamespacedName] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	works := d.works[capp]
+	delete(d.works, capp)
+	if works == nil {
+		return sets.New[types.NamespacedName]()
+	}
+	return works
+}
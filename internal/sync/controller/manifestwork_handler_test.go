@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestManifestWorkPredicateFuncs(t *testing.T) {
+	mw := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-mw",
+			Namespace:  "cluster1",
+			Generation: 1,
+			Labels: map[string]string{
+				utils.MangedByLableKey:      utils.MangedByLabelValue,
+				utils.CappNameLabelKey:      "test-capp",
+				utils.CappNamespaceLabelKey: "test-namespace",
+			},
+		},
+	}
+	statusUpdate := mw.DeepCopy()
+	statusUpdate.Status.Conditions = []metav1.Condition{{Type: workv1.WorkApplied, Status: metav1.ConditionTrue}}
+	specUpdate := mw.DeepCopy()
+	specUpdate.Generation = 2
+	unmanaged := mw.DeepCopy()
+	unmanaged.Labels = nil
+
+	// Assert that only deletions and spec changes of managed ManifestWorks are handled
+	assert.True(t, ManifestWorkPredicateFuncs.Delete(event.DeleteEvent{Object: mw}))
+	assert.True(t, ManifestWorkPredicateFuncs.Update(event.UpdateEvent{ObjectOld: mw, ObjectNew: specUpdate}))
+	assert.False(t, ManifestWorkPredicateFuncs.Update(event.UpdateEvent{ObjectOld: mw, ObjectNew: statusUpdate}))
+	assert.False(t, ManifestWorkPredicateFuncs.Delete(event.DeleteEvent{Object: unmanaged}))
+	assert.Equal(t, types.NamespacedName{Name: "test-capp", Namespace: "test-namespace"}, getManifestWorkCapp(mw))
+}
+
+func TestDeletedManifestWorks(t *testing.T) {
+	deleted := deletedManifestWorks{}
+	capp := types.NamespacedName{Name: "test-capp", Namespace: "test-namespace"}
+	mw := types.NamespacedName{Name: "test-mw", Namespace: "cluster1"}
+
+	deleted.add(capp, mw)
+
+	// Assert that deletions are returned once
+	assert.True(t, deleted.pop(capp).Has(mw))
+	assert.False(t, deleted.pop(capp).Has(mw))
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NamespacePredicateFuncs filters the events of hub namespaces whose labels or annotations changed,
+// as they may be propagated to the managed clusters.
+var NamespacePredicateFuncs = predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
+// ResourceQuotaPredicateFuncs filters out the status updates of ResourceQuotas, which are not propagated
+// to the managed clusters and change whenever the usage of the namespace changes.
+var ResourceQuotaPredicateFuncs = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldQuota, newQuota := e.ObjectOld.(*corev1.ResourceQuota), e.ObjectNew.(*corev1.ResourceQuota)
+		return !reflect.DeepEqual(oldQuota.Spec, newQuota.Spec) ||
+			!reflect.DeepEqual(oldQuota.Labels, newQuota.Labels) ||
+			!reflect.DeepEqual(oldQuota.Annotations, newQuota.Annotations)
+	},
+}
+
+// namespaceHandler enqueues the placed Capps of a hub namespace on the events of the namespace,
+// and of the ResourceQuotas and LimitRanges in it, so that their propagation to the managed clusters
+// does not wait for a change of the Capps.
+func (r *SyncReconciler) namespaceHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(r.getNamespaceCappRequests)
+}
+
+// getNamespaceCappRequests returns the requests of the placed Capps in the namespace of the object,
+// or in the namespace itself if the object is a namespace.
+func (r *SyncReconciler) getNamespaceCappRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespace := obj.GetNamespace()
+	if _, ok := obj.(*corev1.Namespace); ok {
+		namespace = obj.GetName()
+	}
+
+	capps := cappv1alpha1.CappList{}
+	if err := r.Client.List(ctx, &capps, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Capps of namespace", "namespace", namespace)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, capp := range capps.Items {
+		if utils.ContainsPlacementAnnotation(capp) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: capp.Name, Namespace: capp.Namespace}})
+		}
+	}
+	return requests
+}
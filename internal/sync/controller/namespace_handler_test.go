@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetNamespaceCappRequests(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	placed := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "placed", Namespace: "test-ns",
+		Annotations: map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"}}}
+	unplaced := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "unplaced", Namespace: "test-ns"}}
+	other := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other-ns",
+		Annotations: map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"}}}
+	r := SyncReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(placed, unplaced, other).Build()}
+
+	// Assert that only the placed Capps of the namespace are enqueued, for both namespaces and the objects in them
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "placed", Namespace: "test-ns"}}}
+	assert.Equal(t, expected, r.getNamespaceCappRequests(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}))
+	assert.Equal(t, expected, r.getNamespaceCappRequests(context.TODO(), &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "test-ns"}}))
+}
+
+func TestResourceQuotaPredicateFuncs(t *testing.T) {
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-ns"}}
+	statusUpdate := quota.DeepCopy()
+	statusUpdate.Status.Used = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+	specUpdate := quota.DeepCopy()
+	specUpdate.Spec.Hard = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+
+	// Assert that only changes to the propagated content of ResourceQuotas are handled
+	assert.False(t, ResourceQuotaPredicateFuncs.Update(event.UpdateEvent{ObjectOld: quota, ObjectNew: statusUpdate}))
+	assert.True(t, ResourceQuotaPredicateFuncs.Update(event.UpdateEvent{ObjectOld: quota, ObjectNew: specUpdate}))
+}
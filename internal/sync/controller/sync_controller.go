@@ -5,19 +5,25 @@ import (
 	"fmt"
 	"time"
 
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
 	"github.com/dana-team/rcs-ocm-deployer/internal/sync/adapters"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	director "github.com/dana-team/rcs-ocm-deployer/internal/sync/directors"
 	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils/events"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils/metrics"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	workv1 "open-cluster-management.io/api/work/v1"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,6 +35,9 @@ const (
 	controllerName = "SyncController"
 
 	RequeueTime = 2 * time.Second
+
+	// DeferralRequeueTime is the time after which a Capp whose updates are deferred is checked again
+	DeferralRequeueTime = time.Minute
 )
 
 // SyncReconciler reconciles a CappNamespace object
@@ -36,6 +45,8 @@ type SyncReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
 	EventRecorder record.EventRecorder
+
+	deletedManifestWorks deletedManifestWorks
 }
 
 //+kubebuilder:rbac:groups=rcs.dana.io,resources=capps/status,verbs=update
@@ -43,29 +54,94 @@ type SyncReconciler struct {
 //+kubebuilder:rbac:groups="rcs.dana.io",resources=rcsconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
-//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;delete
 
 func (r *SyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx).WithValues("CappName", req.Name, "CappNamespace", req.Namespace)
 	capp := cappv1alpha1.Capp{}
 	if err := r.Client.Get(ctx, req.NamespacedName, &capp); err != nil {
 		if errors.IsNotFound(err) {
+			r.deletedManifestWorks.pop(req.NamespacedName)
+			metrics.SyncPausedCapps.DeleteLabelValues(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 	if capp.ObjectMeta.DeletionTimestamp != nil {
-		if err := adapters.HandleCappDeletion(ctx, capp, logger, r.Client); err != nil {
+		r.deletedManifestWorks.pop(req.NamespacedName)
+		metrics.SyncPausedCapps.DeleteLabelValues(req.Namespace, req.Name)
+		config, err := r.getRCSConfig(ctx)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get RCS Config: %v", err.Error())
+		}
+		if err := adapters.HandleCappDeletion(ctx, capp, adapters.GetDeletionTimeout(*config), logger, r.Client); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: RequeueTime}, nil
 	}
+	if !utils.ContainsPlacementAnnotation(capp) {
+		return ctrl.Result{}, nil
+	}
 	if err := adapters.EnsureFinalizer(ctx, capp, r.Client); err != nil {
 		return ctrl.Result{}, err
 	}
+	if revision, ok := capp.Annotations[utils.AnnotationKeyRollbackToRevision]; ok {
+		return ctrl.Result{}, r.rollback(ctx, capp, revision, logger)
+	}
+	if utils.IsSyncPaused(capp) {
+		return ctrl.Result{}, r.pauseSync(ctx, capp, logger)
+	}
+	if err := r.resumeSync(ctx, capp); err != nil {
+		return ctrl.Result{}, err
+	}
 	return r.SyncManifestWork(capp, ctx, logger)
 }
 
+// rollback restores the spec and the ConfigMaps of the Capp from the specified revision, and records the result in an event.
+// If the rollback fails, the rollback annotation is removed so that the Capp keeps being synced as is.
+func (r *SyncReconciler) rollback(ctx context.Context, capp cappv1alpha1.Capp, revision string, logger logr.Logger) error {
+	configMapsRestored, err := adapters.RollbackCapp(ctx, r.Client, capp, revision)
+	if err != nil {
+		logger.Error(err, "failed to roll back Capp", "revision", revision)
+		r.EventRecorder.Event(&capp, corev1.EventTypeWarning, events.EventCappRollbackFailed, err.Error())
+		delete(capp.Annotations, utils.AnnotationKeyRollbackToRevision)
+		if err := r.Update(ctx, &capp); err != nil {
+			return fmt.Errorf("failed to remove rollback annotation from Capp: %v", err.Error())
+		}
+		return nil
+	}
+	logger.Info("Rolled back Capp", "revision", revision)
+	if !configMapsRestored {
+		r.EventRecorder.Event(&capp, corev1.EventTypeWarning, events.EventCappRolledBack,
+			fmt.Sprintf("Rolled back the spec of the Capp to revision %s, without its ConfigMaps, which were too large to be recorded", revision))
+		return nil
+	}
+	r.EventRecorder.Event(&capp, corev1.EventTypeNormal, events.EventCappRolledBack, fmt.Sprintf("Rolled back Capp to revision %s", revision))
+	return nil
+}
+
+// pauseSync reports that the sync of the Capp is paused, leaving its ManifestWorks as they are on the managed cluster.
+func (r *SyncReconciler) pauseSync(ctx context.Context, capp cappv1alpha1.Capp, logger logr.Logger) error {
+	logger.Info("Sync of Capp is paused, skipping ManifestWork sync")
+	metrics.SyncPausedCapps.WithLabelValues(capp.Namespace, capp.Name).Set(1)
+	return adapters.SetCappCondition(ctx, r.Client, &capp, metav1.Condition{
+		Type:    adapters.ConditionTypeSyncPaused,
+		Status:  metav1.ConditionTrue,
+		Reason:  adapters.ReasonPausedByAnnotation,
+		Message: fmt.Sprintf("Changes to the Capp are not synced to the managed cluster until the %q annotation is removed", utils.AnnotationKeySyncPaused),
+	})
+}
+
+// resumeSync removes the report that the sync of the Capp is paused, if it was, so that its accumulated
+// changes are synced to the managed cluster.
+func (r *SyncReconciler) resumeSync(ctx context.Context, capp cappv1alpha1.Capp) error {
+	metrics.SyncPausedCapps.DeleteLabelValues(capp.Namespace, capp.Name)
+	return adapters.RemoveCappCondition(ctx, r.Client, &capp, adapters.ConditionTypeSyncPaused)
+}
+
 var CappPredicateFuncs = predicate.Funcs{
 	UpdateFunc: func(e event.UpdateEvent) bool {
 		newCapp := e.ObjectNew.(*cappv1alpha1.Capp)
@@ -82,42 +158,245 @@ var CappPredicateFuncs = predicate.Funcs{
 	},
 }
 
-// SyncManifestWork checks whether the manifest work deploying the Capp exists in the managed cluster namespace
-// If it does, it updates the Capp in the manifest work spec. If it doesn't then it creates it
+// SyncManifestWork checks whether the manifest works deploying the Capp exist in the managed cluster namespace.
+// The manifests of the Capp are distributed across several manifest works when they exceed the size limit.
+// If a manifest work exists, it applies its part of the manifests, unless the hash of the manifest work shows
+// it is already up to date. If it doesn't then it creates it, once the previous part has been applied.
+// Manifest works which were deleted or edited out-of-band are recreated or reverted, and the drift is recorded in an event.
+// Updates of existing manifest works are held while the current generation of the Capp awaits approval.
+// Once the Capp has been deployed, creating its missing manifest works updates the Capp, and is held or deferred as such.
 func (r *SyncReconciler) SyncManifestWork(capp cappv1alpha1.Capp, ctx context.Context, logger logr.Logger) (ctrl.Result, error) {
-	mwName := adapters.GenerateMWName(capp)
 	managedClusterName := capp.Annotations[utils.AnnotationKeyHasPlacement]
-	var mw workv1.ManifestWork
+	config, err := r.getRCSConfig(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get RCS Config: %v", err.Error())
+	}
 	cappDirector := director.CappDirector{Ctx: ctx, K8sclient: r.Client, Log: logger, EventRecorder: r.EventRecorder}
 	manifests, err := cappDirector.AssembleManifests(capp)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to build ManifestWork: %v", err.Error())
 	}
+	parts, err := adapters.SplitManifests(manifests, adapters.GetManifestWorkSizeLimit(*config))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to split ManifestWork: %v", err.Error())
+	}
+	desiredMWs, err := adapters.GenerateManifestWorks(capp, managedClusterName, parts, config.Spec.UpdateStrategies)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-	if err := r.Get(ctx, types.NamespacedName{Name: mwName, Namespace: managedClusterName}, &mw); err != nil {
-		if errors.IsNotFound(err) {
-			err := adapters.CreateManifestWork(capp, managedClusterName, logger, r.Client, ctx, r.EventRecorder, manifests)
+	deferral := r.getUpdateDeferral(capp, *config, logger)
+
+	approvalRequired, err := r.isApprovalRequired(ctx, capp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	deployed, err := r.isDeployed(ctx, capp, managedClusterName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	deletedMWs := r.deletedManifestWorks.pop(client.ObjectKeyFromObject(&capp))
+	allApplied := true
+	deferred := false
+	awaitingApproval := false
+	var previousMW *workv1.ManifestWork
+	for _, desiredMW := range desiredMWs {
+		mw := workv1.ManifestWork{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(desiredMW), &mw); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			if deletedMWs.Has(client.ObjectKeyFromObject(desiredMW)) {
+				r.EventRecorder.Event(&capp, corev1.EventTypeWarning, events.EventCappManifestWorkDrifted,
+					fmt.Sprintf("ManifestWork %q was deleted out-of-band, recreating it", desiredMW.Name))
+			}
+			if deployed && approvalRequired {
+				logger.Info(fmt.Sprintf("Holding creation of ManifestWork %q until generation %d of the Capp is approved", desiredMW.Name, capp.Generation))
+				awaitingApproval = true
+				continue
+			}
+			if deployed && deferral != nil {
+				logger.Info(fmt.Sprintf("Deferring creation of ManifestWork %q: %s", desiredMW.Name, deferral.Message))
+				deferred = true
+				continue
+			}
+			if previousMW != nil && !adapters.IsManifestWorkApplied(*previousMW) {
+				logger.Info(fmt.Sprintf("Waiting for ManifestWork %q to be applied before creating ManifestWork %q", previousMW.Name, desiredMW.Name))
+				return ctrl.Result{RequeueAfter: RequeueTime}, nil
+			}
+			if err := adapters.CreateManifestWork(capp, desiredMW, logger, r.Client, ctx, r.EventRecorder); err != nil {
+				return ctrl.Result{}, err
+			}
+			metrics.ManifestWorkSyncs.WithLabelValues(metrics.SyncResultApplied).Inc()
+			allApplied = false
+			previousMW = desiredMW
+			continue
+		}
+
+		previousMW = &mw
+		allApplied = allApplied && adapters.IsManifestWorkApplied(mw)
+		if adapters.IsManifestWorkUpToDate(mw, *desiredMW) {
+			drifted, err := adapters.IsManifestWorkDrifted(mw)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to check ManifestWork for drift: %v", err.Error())
+			}
+			if !drifted {
+				metrics.ManifestWorkSyncs.WithLabelValues(metrics.SyncResultSkipped).Inc()
+				continue
+			}
+			r.EventRecorder.Event(&capp, corev1.EventTypeWarning, events.EventCappManifestWorkDrifted,
+				fmt.Sprintf("ManifestWork %q was edited out-of-band, reverting it", mw.Name))
+		} else if approvalRequired {
+			logger.Info(fmt.Sprintf("Holding update of ManifestWork %q until generation %d of the Capp is approved", mw.Name, capp.Generation))
+			awaitingApproval = true
+			continue
+		} else if deferral != nil {
+			logger.Info(fmt.Sprintf("Deferring update of ManifestWork %q: %s", mw.Name, deferral.Message))
+			deferred = true
+			continue
+		}
+		if err := adapters.ApplyManifestWork(ctx, r.Client, desiredMW); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to sync ManifestWork: %v", err.Error())
+		}
+		metrics.ManifestWorkSyncs.WithLabelValues(metrics.SyncResultApplied).Inc()
+	}
+
+	if awaitingApproval {
+		if err := r.holdForApproval(ctx, capp); err != nil {
 			return ctrl.Result{}, err
 		}
+	} else if err := adapters.RemoveCappCondition(ctx, r.Client, &capp, adapters.ConditionTypeApprovalPending); err != nil {
 		return ctrl.Result{}, err
 	}
-	mw.Spec.Workload.Manifests = manifests
+	if deferred {
+		return ctrl.Result{RequeueAfter: DeferralRequeueTime}, adapters.SetCappCondition(ctx, r.Client, &capp, metav1.Condition{
+			Type:    adapters.ConditionTypePending,
+			Status:  metav1.ConditionTrue,
+			Reason:  deferral.Reason,
+			Message: deferral.Message,
+		})
+	}
+	if err := adapters.RemoveCappCondition(ctx, r.Client, &capp, adapters.ConditionTypePending); err != nil {
+		return ctrl.Result{}, err
+	}
+	if awaitingApproval {
+		return ctrl.Result{}, nil
+	}
 
-	if err = r.Update(ctx, &mw); err != nil {
-		if errors.IsConflict(err) {
-			logger.Info("Conflict while updating ManifestWork trying again in a few seconds")
-			return ctrl.Result{RequeueAfter: RequeueTime}, nil
+	if err := adapters.DeleteSurplusManifestWorkParts(ctx, r.Client, capp, managedClusterName, len(desiredMWs), logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := adapters.RecordRevision(ctx, r.Client, r.Scheme, capp, manifests, adapters.GetRevisionHistoryLimit(*config)); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.releaseLegacyManifestWorks(ctx, capp, managedClusterName, allApplied, logger)
+}
+
+// isDeployed checks if the Capp has been deployed to the managed cluster, which is the case when some of its
+// ManifestWorks exist, or when a revision of the Capp was recorded and all its ManifestWorks were deleted out-of-band.
+func (r *SyncReconciler) isDeployed(ctx context.Context, capp cappv1alpha1.Capp, managedClusterName string) (bool, error) {
+	mws, err := adapters.ListManifestWorkParts(ctx, r.Client, capp, managedClusterName)
+	if err != nil {
+		return false, err
+	}
+	if len(mws) > 0 {
+		return true, nil
+	}
+	revisions, err := adapters.ListRevisions(ctx, r.Client, capp)
+	if err != nil {
+		return false, err
+	}
+	return len(revisions) > 0, nil
+}
+
+// isApprovalRequired checks if updates of the ManifestWorks of the Capp are held until its current generation
+// is approved, which is the case in namespaces requiring approval when the generation has not been approved yet.
+func (r *SyncReconciler) isApprovalRequired(ctx context.Context, capp cappv1alpha1.Capp) (bool, error) {
+	if adapters.IsCappApproved(capp) {
+		return false, nil
+	}
+	return adapters.IsApprovalRequired(ctx, r.Client, capp.Namespace)
+}
+
+// holdForApproval reports that changes to the Capp are held until they are approved. The pending diff
+// is recorded in an event once for each generation of the Capp.
+func (r *SyncReconciler) holdForApproval(ctx context.Context, capp cappv1alpha1.Capp) error {
+	condition := meta.FindStatusCondition(capp.Status.Conditions, adapters.ConditionTypeApprovalPending)
+	if condition == nil || condition.ObservedGeneration != capp.Generation {
+		diff, err := adapters.GeneratePendingDiff(ctx, r.Client, capp)
+		if err != nil {
+			return err
+		}
+		message := fmt.Sprintf("Changes to generation %d of the Capp await approval", capp.Generation)
+		if diff != "" {
+			message = fmt.Sprintf("%s:\n%s", message, diff)
 		}
-		return ctrl.Result{}, fmt.Errorf("failed to sync ManifestWork: %v", err.Error())
+		r.EventRecorder.Event(&capp, corev1.EventTypeNormal, events.EventCappApprovalPending, message)
 	}
-	return ctrl.Result{}, err
+	return adapters.SetCappCondition(ctx, r.Client, &capp, metav1.Condition{
+		Type:   adapters.ConditionTypeApprovalPending,
+		Status: metav1.ConditionTrue,
+		Reason: adapters.ReasonAwaitingApproval,
+		Message: fmt.Sprintf("Changes to the Capp are not synced to the managed cluster until the %q annotation is set to %d",
+			utils.AnnotationKeyApprovedGeneration, capp.Generation),
+	})
+}
+
+// getUpdateDeferral returns why the updates of the ManifestWorks of the Capp are deferred, or nil if they are not
+// or if the Capp has the deploy-override annotation.
+func (r *SyncReconciler) getUpdateDeferral(capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig, logger logr.Logger) *adapters.UpdateDeferral {
+	if capp.Annotations[utils.AnnotationKeyDeployOverride] == "true" {
+		return nil
+	}
+	return adapters.GetUpdateDeferral(config, capp.Namespace, time.Now(), logger)
+}
+
+// releaseLegacyManifestWorks deletes the ManifestWorks of the Capp which were created under the legacy naming
+// scheme, while orphaning their resources. This is only done once all the current ManifestWorks have been applied,
+// so that the resources on the managed cluster are adopted rather than deleted.
+func (r *SyncReconciler) releaseLegacyManifestWorks(ctx context.Context, capp cappv1alpha1.Capp, managedClusterName string, allApplied bool, logger logr.Logger) (ctrl.Result, error) {
+	legacyMWs, err := adapters.ListLegacyManifestWorks(ctx, r.Client, capp, managedClusterName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(legacyMWs) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if !allApplied {
+		logger.Info("Waiting for ManifestWorks to be applied before releasing legacy ManifestWorks")
+		return ctrl.Result{RequeueAfter: RequeueTime}, nil
+	}
+	if err := adapters.ReleaseLegacyManifestWorks(ctx, r.Client, legacyMWs, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// getRCSConfig returns the RCS Config, or an empty RCS Config if it has not been defined.
+func (r *SyncReconciler) getRCSConfig(ctx context.Context) (*rcsv1alpha1.RCSConfig, error) {
+	config, err := utils.GetRCSConfig(ctx, r.Client)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &rcsv1alpha1.RCSConfig{}, nil
+		}
+		return nil, err
+	}
+	return config, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := adapters.IndexManifestWorksByCapp(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("failed to index ManifestWorks by Capp: %v", err.Error())
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&cappv1alpha1.Capp{}).
+		For(&cappv1alpha1.Capp{}, builder.WithPredicates(CappPredicateFuncs)).
+		Watches(&workv1.ManifestWork{}, r.manifestWorkHandler(), builder.WithPredicates(ManifestWorkPredicateFuncs)).
+		Watches(&corev1.Namespace{}, r.namespaceHandler(), builder.WithPredicates(NamespacePredicateFuncs)).
+		Watches(&corev1.ResourceQuota{}, r.namespaceHandler(), builder.WithPredicates(ResourceQuotaPredicateFuncs)).
+		Watches(&corev1.LimitRange{}, r.namespaceHandler()).
 		Named(controllerName).
-		WithEventFilter(CappPredicateFuncs).
 		Complete(r)
 }
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/sync/adapters"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newSyncClient returns a fake client holding the specified objects, which emulates server-side apply
+// by creating or replacing the applied objects.
+func newSyncClient(t *testing.T, objects ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, rbacv1.AddToScheme(scheme))
+	assert.NoError(t, workv1.AddToScheme(scheme))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&cappv1alpha1.Capp{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch.Type() != types.ApplyPatchType {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+				existing := obj.DeepCopyObject().(client.Object)
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+					if !errors.IsNotFound(err) {
+						return err
+					}
+					return c.Create(ctx, obj)
+				}
+				obj.SetResourceVersion(existing.GetResourceVersion())
+				return c.Update(ctx, obj)
+			},
+		}).Build()
+	return fakeClient, scheme
+}
+
+// listManifestWorks returns the ManifestWorks in the managed cluster namespace, after marking them as applied.
+func listManifestWorks(t *testing.T, ctx context.Context, k8sClient client.Client, managedClusterName string) []workv1.ManifestWork {
+	mwList := workv1.ManifestWorkList{}
+	assert.NoError(t, k8sClient.List(ctx, &mwList, client.InNamespace(managedClusterName)))
+	for i := range mwList.Items {
+		meta.SetStatusCondition(&mwList.Items[i].Status.Conditions, metav1.Condition{Type: workv1.WorkApplied, Status: metav1.ConditionTrue, Reason: "Applied"})
+		assert.NoError(t, k8sClient.Update(ctx, &mwList.Items[i]))
+	}
+	return mwList.Items
+}
+
+func TestPauseAndResumeSync(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-capp",
+			Namespace: "test-namespace",
+			Annotations: map[string]string{
+				utils.AnnotationKeyHasPlacement: "cluster1",
+				utils.AnnotationKeySyncPaused:   "true",
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(capp).WithStatusSubresource(&cappv1alpha1.Capp{}).Build()
+	r := SyncReconciler{Client: fakeClient, Scheme: scheme, EventRecorder: record.NewFakeRecorder(10)}
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+
+	// Assert that a paused Capp reports the SyncPaused condition
+	assert.NoError(t, r.pauseSync(ctx, getCapp(), logr.Discard()))
+	condition := meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypeSyncPaused)
+	assert.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// Assert that resuming removes the SyncPaused condition
+	assert.NoError(t, r.resumeSync(ctx, getCapp()))
+	assert.Nil(t, meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypeSyncPaused))
+}
+
+func TestHoldForApproval(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-namespace", Labels: map[string]string{utils.ApprovalRequiredLabelKey: "true"}}}
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-capp",
+			Namespace:   "test-namespace",
+			Generation:  2,
+			Annotations: map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, capp).WithStatusSubresource(&cappv1alpha1.Capp{}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := SyncReconciler{Client: fakeClient, Scheme: scheme, EventRecorder: recorder}
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+
+	// Assert that an unapproved Capp in a namespace requiring approval is held
+	required, err := r.isApprovalRequired(ctx, getCapp())
+	assert.NoError(t, err)
+	assert.True(t, required)
+
+	// Assert that holding the Capp reports the ApprovalPending condition, with a single event per generation
+	assert.NoError(t, r.holdForApproval(ctx, getCapp()))
+	assert.NoError(t, r.holdForApproval(ctx, getCapp()))
+	condition := meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypeApprovalPending)
+	assert.NotNil(t, condition)
+	assert.Equal(t, adapters.ReasonAwaitingApproval, condition.Reason)
+	assert.Len(t, recorder.Events, 1)
+
+	// Assert that the approved generation of the Capp is not held
+	approved := getCapp()
+	approved.Annotations[utils.AnnotationKeyApprovedGeneration] = "2"
+	required, err = r.isApprovalRequired(ctx, approved)
+	assert.NoError(t, err)
+	assert.False(t, required)
+}
+
+func TestDeferCreationOfNewParts(t *testing.T) {
+	ctx := context.TODO()
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-namespace"}}
+	config := &rcsv1alpha1.RCSConfig{ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace}}
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-capp",
+			Namespace:   "test-namespace",
+			Generation:  1,
+			Annotations: map[string]string{utils.AnnotationKeyHasPlacement: "cluster1"},
+		},
+	}
+	fakeClient, scheme := newSyncClient(t, namespace, config, capp)
+	r := SyncReconciler{Client: fakeClient, Scheme: scheme, EventRecorder: record.NewFakeRecorder(10)}
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+
+	// Assert that the first part of the Capp is created
+	_, err := r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+	assert.NoError(t, err)
+	assert.Len(t, listManifestWorks(t, ctx, fakeClient, "cluster1"), 1)
+
+	// Assert that growing the number of parts during a deploy freeze does not create the new parts
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(config), config))
+	limit := resource.MustParse("1")
+	config.Spec.ManifestWorkSizeLimit = &limit
+	config.Spec.DeployFreezes = []rcsv1alpha1.DeployFreeze{{
+		Name:       "test-freeze",
+		TimeWindow: rcsv1alpha1.TimeWindow{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}},
+	}}
+	assert.NoError(t, fakeClient.Update(ctx, config))
+	_, err = r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+	assert.NoError(t, err)
+	assert.Len(t, listManifestWorks(t, ctx, fakeClient, "cluster1"), 1)
+	condition := meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypePending)
+	assert.NotNil(t, condition)
+	assert.Equal(t, adapters.ReasonDeployFreeze, condition.Reason)
+
+	// Assert that the new parts are created once the freeze is lifted
+	config.Spec.DeployFreezes = nil
+	assert.NoError(t, fakeClient.Update(ctx, config))
+	for i := 0; i < 5; i++ {
+		_, err = r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+		assert.NoError(t, err)
+		listManifestWorks(t, ctx, fakeClient, "cluster1")
+	}
+	assert.Greater(t, len(listManifestWorks(t, ctx, fakeClient, "cluster1")), 1)
+	assert.Nil(t, meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypePending))
+}
+
+func TestHoldCreationOfNewParts(t *testing.T) {
+	ctx := context.TODO()
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-namespace", Labels: map[string]string{utils.ApprovalRequiredLabelKey: "true"}}}
+	config := &rcsv1alpha1.RCSConfig{ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace}}
+	capp := &cappv1alpha1.Capp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-capp",
+			Namespace:  "test-namespace",
+			Generation: 1,
+			Annotations: map[string]string{
+				utils.AnnotationKeyHasPlacement:       "cluster1",
+				utils.AnnotationKeyApprovedGeneration: "1",
+			},
+		},
+	}
+	fakeClient, scheme := newSyncClient(t, namespace, config, capp)
+	r := SyncReconciler{Client: fakeClient, Scheme: scheme, EventRecorder: record.NewFakeRecorder(10)}
+	getCapp := func() cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{}
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKey{Name: "test-capp", Namespace: "test-namespace"}, &capp))
+		return capp
+	}
+
+	// Assert that the approved generation of the Capp is deployed
+	_, err := r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+	assert.NoError(t, err)
+	mws := listManifestWorks(t, ctx, fakeClient, "cluster1")
+	assert.Len(t, mws, 1)
+
+	// Assert that growing the number of parts while approval is pending does not create the new parts
+	current := getCapp()
+	current.Generation = 2
+	current.Spec.ConfigurationSpec.Template.Spec.Containers = []corev1.Container{{Name: "test", Image: "test-image:v2"}}
+	assert.NoError(t, fakeClient.Update(ctx, &current))
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(config), config))
+	limit := resource.MustParse("1")
+	config.Spec.ManifestWorkSizeLimit = &limit
+	assert.NoError(t, fakeClient.Update(ctx, config))
+	_, err = r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+	assert.NoError(t, err)
+	assert.Len(t, listManifestWorks(t, ctx, fakeClient, "cluster1"), 1)
+	assert.NotNil(t, meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypeApprovalPending))
+
+	// Assert that a ManifestWork deleted out-of-band is not recreated while approval is pending
+	assert.NoError(t, fakeClient.Delete(ctx, &mws[0]))
+	_, err = r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+	assert.NoError(t, err)
+	assert.Empty(t, listManifestWorks(t, ctx, fakeClient, "cluster1"))
+
+	// Assert that the parts are created once the generation is approved
+	current = getCapp()
+	current.Annotations[utils.AnnotationKeyApprovedGeneration] = "2"
+	assert.NoError(t, fakeClient.Update(ctx, &current))
+	for i := 0; i < 5; i++ {
+		_, err = r.SyncManifestWork(getCapp(), ctx, logr.Discard())
+		assert.NoError(t, err)
+		listManifestWorks(t, ctx, fakeClient, "cluster1")
+	}
+	assert.Greater(t, len(listManifestWorks(t, ctx, fakeClient, "cluster1")), 1)
+	assert.Nil(t, meta.FindStatusCondition(getCapp().Status.Conditions, adapters.ConditionTypeApprovalPending))
+}
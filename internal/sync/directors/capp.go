@@ -21,12 +21,20 @@ type CappDirector struct {
 }
 
 // AssembleManifests compiles a comprehensive list of mw1.Manifest objects necessary for deploying a given capp.
-// It begins by building basic capp and namespace manifests, then delegates to the VolumesDirector and AuthDirector
-// to gather additional manifests related to volumes and authentication respectively. In case of errors in assembling
-// volume or authentication manifests, it records an event and returns the encountered error.
+// It begins by building the basic capp manifest, then delegates to the NamespaceDirector, VolumesDirector and AuthDirector
+// to gather additional manifests related to the namespace, volumes and authentication respectively. In case of errors in
+// assembling namespace, volume or authentication manifests, it records an event and returns the encountered error.
 // This method provides a central point for collating all necessary Kubernetes manifests for a capp deployment.
 func (d CappDirector) AssembleManifests(capp cappv1alpha1.Capp) ([]workv1.Manifest, error) {
-	manifests := []workv1.Manifest{builder.BuildCapp(capp), builder.BuildNamespace(capp.Namespace)}
+	manifests := []workv1.Manifest{builder.BuildCapp(capp)}
+	namespaceDirector := NamespaceDirector(d)
+	namespaceManifests, err := namespaceDirector.AssembleManifests(capp)
+	if err != nil {
+		d.EventRecorder.Event(&capp, corev1.EventTypeWarning, events.EventCappNamespacePropagationFailed, err.Error())
+		return []workv1.Manifest{}, err
+	}
+	manifests = append(manifests, namespaceManifests...)
+
 	volumesDirector := VolumesDirector(d)
 	volumesManifests, err := volumesDirector.AssembleManifests(capp)
 	if err != nil {
@@ -0,0 +1,98 @@
+package directors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	builder "github.com/dana-team/rcs-ocm-deployer/internal/sync/builders"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type NamespaceDirector struct {
+	Ctx           context.Context
+	K8sclient     client.Client
+	Log           logr.Logger
+	EventRecorder record.EventRecorder
+}
+
+// AssembleManifests compiles a slice of manifests for the namespace of the capp on the managed cluster.
+// The namespace carries the labels and annotations of the hub namespace allowed by the namespace propagation
+// of the RCS Config, followed by the ResourceQuotas and LimitRanges of the hub namespace, sorted by name, if they are propagated.
+func (d NamespaceDirector) AssembleManifests(capp cappv1alpha1.Capp) ([]workv1.Manifest, error) {
+	propagation, err := d.getNamespacePropagation()
+	if err != nil {
+		return []workv1.Manifest{}, err
+	}
+	if propagation == nil {
+		return []workv1.Manifest{builder.BuildNamespace(capp.Namespace)}, nil
+	}
+
+	namespace := corev1.Namespace{}
+	if err := d.K8sclient.Get(d.Ctx, client.ObjectKey{Name: capp.Namespace}, &namespace); err != nil {
+		return []workv1.Manifest{}, fmt.Errorf("unable to fetch namespace of Capp: %v", err.Error())
+	}
+	manifests := []workv1.Manifest{builder.BuildNamespaceWithMetadata(capp.Namespace,
+		filterKeys(namespace.Labels, propagation.Labels), filterKeys(namespace.Annotations, propagation.Annotations))}
+
+	if propagation.ResourceQuotas {
+		quotas := corev1.ResourceQuotaList{}
+		if err := d.K8sclient.List(d.Ctx, &quotas, client.InNamespace(capp.Namespace)); err != nil {
+			return []workv1.Manifest{}, fmt.Errorf("failed to list ResourceQuotas in the namespace: %v", err.Error())
+		}
+		sort.Slice(quotas.Items, func(i, j int) bool { return quotas.Items[i].Name < quotas.Items[j].Name })
+		for _, quota := range quotas.Items {
+			manifests = append(manifests, builder.BuildResourceQuota(quota))
+		}
+	}
+
+	if propagation.LimitRanges {
+		limitRanges := corev1.LimitRangeList{}
+		if err := d.K8sclient.List(d.Ctx, &limitRanges, client.InNamespace(capp.Namespace)); err != nil {
+			return []workv1.Manifest{}, fmt.Errorf("failed to list LimitRanges in the namespace: %v", err.Error())
+		}
+		sort.Slice(limitRanges.Items, func(i, j int) bool { return limitRanges.Items[i].Name < limitRanges.Items[j].Name })
+		for _, limitRange := range limitRanges.Items {
+			manifests = append(manifests, builder.BuildLimitRange(limitRange))
+		}
+	}
+	return manifests, nil
+}
+
+// getNamespacePropagation returns the namespace propagation of the RCS Config, or nil if the RCS Config
+// does not exist or does not define it.
+func (d NamespaceDirector) getNamespacePropagation() (*rcsv1alpha1.NamespacePropagation, error) {
+	config, err := utils.GetRCSConfig(d.Ctx, d.K8sclient)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get RCS Config: %v", err.Error())
+	}
+	return config.Spec.NamespacePropagation, nil
+}
+
+// filterKeys returns the entries of the map whose keys are allowed. An allowed key ending with "*"
+// allows all the keys starting with its prefix.
+func filterKeys(entries map[string]string, allowedKeys []string) map[string]string {
+	filtered := map[string]string{}
+	for key, value := range entries {
+		for _, allowedKey := range allowedKeys {
+			prefix, isPrefix := strings.CutSuffix(allowedKey, "*")
+			if key == allowedKey || (isPrefix && strings.HasPrefix(key, prefix)) {
+				filtered[key] = value
+				break
+			}
+		}
+	}
+	return filtered
+}
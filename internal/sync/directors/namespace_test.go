@@ -0,0 +1,90 @@
+package directors
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNamespaceDirector(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace"}}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-namespace",
+			Labels: map[string]string{
+				"pod-security.kubernetes.io/enforce": "restricted",
+				"tenant":                             "team-a",
+				"internal":                           "true",
+			},
+			Annotations: map[string]string{"openshift.io/node-selector": "zone=a", "internal": "true"},
+		},
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-quota", Namespace: "test-namespace", ResourceVersion: "5"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}},
+	}
+	limitRange := &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: "test-limit-range", Namespace: "test-namespace"}}
+
+	// Assert that only the namespace is shipped when the RCS Config does not define namespace propagation
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, quota, limitRange).Build()
+	namespaceDirector := NamespaceDirector{ctx, fakeClient, logr.Discard(), record.NewFakeRecorder(10)}
+	manifests, err := namespaceDirector.AssembleManifests(capp)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 1)
+	assert.Equal(t, map[string]string{utils.MangedByLableKey: utils.MangedByLabelValue}, manifests[0].Object.(*corev1.Namespace).Labels)
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec: rcsv1alpha1.RCSConfigSpec{NamespacePropagation: &rcsv1alpha1.NamespacePropagation{
+			Labels:         []string{"pod-security.kubernetes.io/*", "tenant"},
+			Annotations:    []string{"openshift.io/node-selector"},
+			ResourceQuotas: true,
+			LimitRanges:    true,
+		}},
+	}
+	fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, quota, limitRange, config).Build()
+	namespaceDirector = NamespaceDirector{ctx, fakeClient, logr.Discard(), record.NewFakeRecorder(10)}
+	manifests, err = namespaceDirector.AssembleManifests(capp)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 3)
+
+	// Assert that only the allowlisted labels and annotations are propagated, along with the managed-by label
+	propagatedNamespace := manifests[0].Object.(*corev1.Namespace)
+	assert.Equal(t, map[string]string{
+		"pod-security.kubernetes.io/enforce": "restricted",
+		"tenant":                             "team-a",
+		utils.MangedByLableKey:               utils.MangedByLabelValue,
+	}, propagatedNamespace.Labels)
+	assert.Equal(t, map[string]string{"openshift.io/node-selector": "zone=a"}, propagatedNamespace.Annotations)
+
+	// Assert that the ResourceQuotas and LimitRanges are propagated without their server-side metadata
+	propagatedQuota := manifests[1].Object.(*corev1.ResourceQuota)
+	assert.Equal(t, "test-quota", propagatedQuota.Name)
+	assert.Empty(t, propagatedQuota.ResourceVersion)
+	assert.Equal(t, quota.Spec, propagatedQuota.Spec)
+	assert.Equal(t, "test-limit-range", manifests[2].Object.(*corev1.LimitRange).Name)
+}
+
+func TestFilterKeys(t *testing.T) {
+	entries := map[string]string{"a/b": "1", "a/c": "2", "d": "3"}
+
+	assert.Equal(t, map[string]string{"a/b": "1", "a/c": "2"}, filterKeys(entries, []string{"a/*"}))
+	assert.Equal(t, map[string]string{"d": "3"}, filterKeys(entries, []string{"d", "e"}))
+	assert.Empty(t, filterKeys(entries, nil))
+}
@@ -0,0 +1,88 @@
+package directors
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// referenceNames returns the names of the references.
+func referenceNames(references []utils.CappReference) []string {
+	var names []string
+	for _, reference := range references {
+		names = append(names, reference.Name)
+	}
+	return names
+}
+
+func TestGetResourceVolumesFromContainerSpec(t *testing.T) {
+	capp := cappv1alpha1.Capp{}
+	podSpec := &capp.Spec.ConfigurationSpec.Template.Spec
+	podSpec.Containers = []corev1.Container{{
+		Name: "app",
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-from-cm"}}},
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-from-secret"}}},
+		},
+		Env: []corev1.EnvVar{
+			{Name: "PLAIN", Value: "value"},
+			{Name: "CM", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "value-from-cm"}}}},
+			{Name: "SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "value-from-secret"}}}},
+		},
+	}}
+	podSpec.Volumes = []corev1.Volume{
+		{Name: "cm", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "volume-cm"}}}},
+		{Name: "secret", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "volume-secret"}}},
+	}
+	podSpec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull-secret"}}
+
+	// Assert that ConfigMaps and Secrets are collected from env, volumes and image pull secrets
+	configMaps, secrets := getResourceVolumesFromContainerSpec(capp)
+	assert.Equal(t, []string{"env-from-cm", "value-from-cm", "volume-cm"}, referenceNames(configMaps))
+	assert.Equal(t, []string{"env-from-secret", "value-from-secret", "volume-secret", "pull-secret"}, referenceNames(secrets))
+}
+
+func TestAssembleVolumesWithOptionalReferences(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-namespace"}}
+	director := VolumesDirector{
+		Ctx:           context.TODO(),
+		K8sclient:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+		Log:           logr.Discard(),
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-namespace"}}
+	podSpec := &capp.Spec.ConfigurationSpec.Template.Spec
+	podSpec.Containers = []corev1.Container{{
+		Name: "app",
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "existing"}}},
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "optional-cm"}, Optional: ptr.To(true)}},
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "optional-secret"}, Optional: ptr.To(true)}},
+		},
+	}}
+
+	// Assert that optional references to missing objects are skipped
+	manifests, err := director.AssembleManifests(capp)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 1)
+
+	// Assert that required references to missing objects still fail
+	podSpec.Volumes = []corev1.Volume{
+		{Name: "secret", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "required-secret"}}},
+	}
+	_, err = director.AssembleManifests(capp)
+	assert.ErrorContains(t, err, "unable to fetch Secret from Capp spec")
+}
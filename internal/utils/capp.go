@@ -14,6 +14,49 @@ var (
 
 	// AnnotationKeyHasPlacement is the key used to store the managed cluster name in an annotation on a Capp resource
 	AnnotationKeyHasPlacement = RCSAPIGroup + "/has-placement"
+
+	// CappNameLabelKey is the key of the label holding the name of the Capp a ManifestWork belongs to
+	CappNameLabelKey = RCSAPIGroup + "/capp-name"
+
+	// CappNamespaceLabelKey is the key of the label holding the namespace of the Capp a ManifestWork belongs to
+	CappNamespaceLabelKey = RCSAPIGroup + "/capp-namespace"
+
+	// CappUIDLabelKey is the key of the label holding the UID of the Capp a ManifestWork belongs to
+	CappUIDLabelKey = RCSAPIGroup + "/capp-uid"
+
+	// AnnotationKeyForceOrphan is the key of the annotation used on a deleted Capp to stop waiting for its
+	// resources to be removed from the managed clusters, and orphan them instead
+	AnnotationKeyForceOrphan = RCSAPIGroup + "/force-orphan"
+
+	// AnnotationKeySyncPaused is the key of the annotation used to pause the sync of a Capp to its managed cluster
+	AnnotationKeySyncPaused = RCSAPIGroup + "/sync-paused"
+
+	// AnnotationKeyRollbackToRevision is the key of the annotation used to roll back a Capp to one of its revisions
+	AnnotationKeyRollbackToRevision = RCSAPIGroup + "/rollback-to-revision"
+
+	// AnnotationKeyDeployOverride is the key of the annotation used to push updates of a Capp to its managed cluster
+	// regardless of maintenance windows and deploy freezes
+	AnnotationKeyDeployOverride = RCSAPIGroup + "/deploy-override"
+
+	// RevisionHashLabelKey is the key of the label holding the hash of the data of a Capp revision
+	RevisionHashLabelKey = RCSAPIGroup + "/revision-hash"
+
+	// ApprovalRequiredLabelKey is the key of the label used on a namespace to hold changes to its placed Capps
+	// until they are approved
+	ApprovalRequiredLabelKey = RCSAPIGroup + "/approval-required"
+
+	// AnnotationKeyApprovedGeneration is the key of the annotation holding the generation of the Capp which was approved
+	AnnotationKeyApprovedGeneration = RCSAPIGroup + "/approved-generation"
+
+	// AnnotationKeyApprovedBy is the key of the annotation holding the username who approved the Capp
+	AnnotationKeyApprovedBy = RCSAPIGroup + "/approved-by"
+
+	// AnnotationKeyLastUpdatedBy is the key of the annotation holding the username who last updated the Capp
+	AnnotationKeyLastUpdatedBy = RCSAPIGroup + "/last-updated-by"
+
+	// AnnotationKeyOriginalImages is the key of the annotation holding the images of the containers of the Capp,
+	// by container name, before their tags were resolved to digests
+	AnnotationKeyOriginalImages = RCSAPIGroup + "/original-images"
 )
 
 const (
@@ -36,3 +79,8 @@ func ContainsPlacementAnnotation(capp cappv1alpha1.Capp) bool {
 	namespace, ok := annotations[AnnotationKeyHasPlacement]
 	return ok && len(namespace) > 0
 }
+
+// IsSyncPaused checks if the sync of a Capp to its managed cluster has been paused using an annotation.
+func IsSyncPaused(capp cappv1alpha1.Capp) bool {
+	return capp.GetAnnotations()[AnnotationKeySyncPaused] == "true"
+}
@@ -0,0 +1,156 @@
+// Package cron provides a parser and matcher of standard 5-field cron expressions.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is the range of values of a field of a cron expression.
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+const (
+	dayOfMonthIndex = 2
+	dayOfWeekIndex  = 4
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	values [5]map[int]bool
+	// restricted holds whether each field is restricted, as opposed to "*".
+	restricted [5]bool
+}
+
+// Parse parses a standard 5-field cron expression: minute, hour, day of month, month and day of week.
+// Every field supports "*", single values, ranges ("1-5"), steps ("*/15", "1-30/5") and lists ("1,15").
+// Day of week 7 is an alias of Sunday (0).
+func Parse(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", expression, len(fields), len(parts))
+	}
+
+	schedule := &Schedule{}
+	for i, part := range parts {
+		values, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", expression, err.Error())
+		}
+		schedule.values[i] = values
+		schedule.restricted[i] = part != "*"
+	}
+	return schedule, nil
+}
+
+// parseField parses a field of a cron expression into the set of values it matches.
+func parseField(expression string, f field) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, item := range strings.Split(expression, ",") {
+		rangeExpression, step := item, 1
+		if before, after, found := strings.Cut(item, "/"); found {
+			var err error
+			if step, err = strconv.Atoi(after); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q in %s field", after, f.name)
+			}
+			rangeExpression = before
+		}
+
+		low, high := f.min, f.max
+		if rangeExpression != "*" {
+			var err error
+			before, after, found := strings.Cut(rangeExpression, "-")
+			if low, err = parseValue(before, f); err != nil {
+				return nil, err
+			}
+			high = low
+			if found {
+				if high, err = parseValue(after, f); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+			if low > high {
+				return nil, fmt.Errorf("invalid range %q in %s field", rangeExpression, f.name)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			if f.name == fields[dayOfWeekIndex].name && value == 7 {
+				values[0] = true
+				continue
+			}
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// parseValue parses a single value of a field of a cron expression.
+func parseValue(expression string, f field) (int, error) {
+	value, err := strconv.Atoi(expression)
+	maxValue := f.max
+	if f.name == fields[dayOfWeekIndex].name {
+		maxValue = 7
+	}
+	if err != nil || value < f.min || value > maxValue {
+		return 0, fmt.Errorf("invalid value %q in %s field", expression, f.name)
+	}
+	return value, nil
+}
+
+// Matches returns whether the schedule matches the minute of the specified time, in the location of the time.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.values[0][t.Minute()] && s.values[1][t.Hour()] && s.values[3][int(t.Month())] && s.matchesDay(t)
+}
+
+// matchesDay returns whether the schedule matches the day of the specified time.
+// As in standard cron, when both the day of month and the day of week are restricted, matching either is enough.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth, dayOfWeek := s.values[dayOfMonthIndex][t.Day()], s.values[dayOfWeekIndex][int(t.Weekday())]
+	if s.restricted[dayOfMonthIndex] && s.restricted[dayOfWeekIndex] {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+// IsActive returns whether a window starting at the times matched by the schedule and lasting for the specified
+// duration is active at the specified time, that is, whether the schedule matched a minute within the duration.
+// Months, days and hours which the schedule does not match are skipped as a whole, so that long windows are cheap.
+func (s *Schedule) IsActive(t time.Time, duration time.Duration) bool {
+	for candidate := t.Truncate(time.Minute); t.Sub(candidate) < duration; {
+		var previous time.Time
+		switch {
+		case !s.values[3][int(candidate.Month())]:
+			previous = time.Date(candidate.Year(), candidate.Month(), 1, 0, 0, 0, 0, candidate.Location())
+		case !s.matchesDay(candidate):
+			previous = time.Date(candidate.Year(), candidate.Month(), candidate.Day(), 0, 0, 0, 0, candidate.Location())
+		case !s.values[1][candidate.Hour()]:
+			previous = candidate.Truncate(time.Hour)
+		case !s.values[0][candidate.Minute()]:
+			previous = candidate
+		default:
+			return true
+		}
+		// The start of the month, day or hour may not precede the candidate around daylight saving time transitions
+		if !previous.Before(candidate) {
+			previous = candidate
+		}
+		candidate = previous.Add(-time.Minute)
+	}
+	return false
+}
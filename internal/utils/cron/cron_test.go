@@ -0,0 +1,65 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for _, expression := range []string{"* * * * *", "*/15 2-4 1,15 * 1-5", "0 22 * * 7", "30 1 * 1-12/3 *"} {
+		_, err := Parse(expression)
+		assert.NoError(t, err, expression)
+	}
+
+	// Assert that invalid expressions are rejected
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	schedule, err := Parse("0 22 * * 1-5")
+	assert.NoError(t, err)
+
+	// Monday, 22:00
+	assert.True(t, schedule.Matches(time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)))
+	// Monday, 22:01
+	assert.False(t, schedule.Matches(time.Date(2024, 1, 1, 22, 1, 0, 0, time.UTC)))
+	// Sunday, 22:00
+	assert.False(t, schedule.Matches(time.Date(2024, 1, 7, 22, 0, 0, 0, time.UTC)))
+
+	// Assert that either the day of month or the day of week is enough when both are restricted
+	schedule, err = Parse("0 0 1 * 0")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Matches(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, schedule.Matches(time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, schedule.Matches(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestIsActive(t *testing.T) {
+	schedule, err := Parse("0 22 * * *")
+	assert.NoError(t, err)
+
+	assert.True(t, schedule.IsActive(time.Date(2024, 1, 1, 22, 0, 30, 0, time.UTC), 2*time.Hour))
+	assert.True(t, schedule.IsActive(time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC), 2*time.Hour))
+	assert.False(t, schedule.IsActive(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 2*time.Hour))
+	assert.False(t, schedule.IsActive(time.Date(2024, 1, 1, 21, 59, 0, 0, time.UTC), 2*time.Hour))
+
+	// Assert that long windows are matched across months, and end after their duration
+	schedule, err = Parse("0 0 31 12 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.IsActive(time.Date(2025, 1, 7, 23, 59, 0, 0, time.UTC), 192*time.Hour))
+	assert.False(t, schedule.IsActive(time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), 192*time.Hour))
+	assert.False(t, schedule.IsActive(time.Date(2025, 12, 30, 23, 59, 0, 0, time.UTC), 192*time.Hour))
+
+	// Assert that windows are matched in the location of the time, across daylight saving time transitions
+	location, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err)
+	schedule, err = Parse("30 1 * * *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.IsActive(time.Date(2024, 10, 27, 3, 0, 0, 0, location), 3*time.Hour))
+	assert.False(t, schedule.IsActive(time.Date(2024, 10, 27, 0, 59, 0, 0, location), time.Hour))
+}
@@ -4,6 +4,13 @@ const (
 	EventCappScheduled                  = "CappScheduled"
 	EventCappVolumeNotFound             = "VolumeNotFound"
 	EventCappAuthFailed                 = "AuthManifestsCreationFailed"
+	EventCappNamespacePropagationFailed = "NamespacePropagationFailed"
 	EventCappManifestWorkCreated        = "ManifestWorkCreated"
 	EventCappManifestWorkCreationFailed = "ManifestWorkCreationFailed"
+	EventCappManifestWorkDrifted        = "ManifestWorkDrifted"
+	EventCappRolledBack                 = "RolledBack"
+	EventCappRollbackFailed             = "RollbackFailed"
+	EventCappApprovalPending            = "ApprovalPending"
+	EventManifestWorkOrphaned           = "ManifestWorkOrphaned"
+	EventOrphanedManifestWorkDeleted    = "OrphanedManifestWorkDeleted"
 )
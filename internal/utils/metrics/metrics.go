@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// SyncResultApplied is the result of a sync in which the ManifestWork was applied
+	SyncResultApplied = "applied"
+	// SyncResultSkipped is the result of a sync in which the ManifestWork was already up to date
+	SyncResultSkipped = "skipped"
+)
+
+var (
+	// ManifestWorkSyncs counts the ManifestWork syncs of the sync controller, partitioned by their result
+	ManifestWorkSyncs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rcs_manifestwork_syncs_total",
+			Help: "Number of ManifestWork syncs performed by the sync controller, partitioned by result",
+		},
+		[]string{"result"},
+	)
+
+	// SyncPausedCapps is set to 1 for every Capp whose sync to its managed cluster is paused
+	SyncPausedCapps = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rcs_sync_paused_capps",
+			Help: "Set to 1 for every Capp whose sync to its managed cluster is paused",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// OrphanedManifestWorks is the number of orphaned ManifestWorks found by the last orphan collection,
+	// partitioned by the reason they are orphaned
+	OrphanedManifestWorks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rcs_orphaned_manifestworks",
+			Help: "Number of orphaned ManifestWorks found by the last orphan collection, partitioned by reason",
+		},
+		[]string{"reason"},
+	)
+
+	// OrphanedManifestWorkDeletions counts the orphaned ManifestWorks deleted by the orphan collector
+	OrphanedManifestWorkDeletions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rcs_orphaned_manifestwork_deletions_total",
+			Help: "Number of orphaned ManifestWorks deleted by the orphan collector",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ManifestWorkSyncs, SyncPausedCapps, OrphanedManifestWorks, OrphanedManifestWorkDeletions)
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetRCSConfig returns the instance of RCS Config.
+func GetRCSConfig(ctx context.Context, k8sClient client.Client) (*v1alpha1.RCSConfig, error) {
+	config := v1alpha1.RCSConfig{}
+	key := types.NamespacedName{Name: RCSConfigName, Namespace: RCSConfigNamespace}
+	if err := k8sClient.Get(ctx, key, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
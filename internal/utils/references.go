@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ReferenceKindConfigMap is the kind of a reference to a ConfigMap
+	ReferenceKindConfigMap = "ConfigMap"
+
+	// ReferenceKindSecret is the kind of a reference to a Secret
+	ReferenceKindSecret = "Secret"
+
+	// podSpecFieldPath is the field path of the pod spec within a Capp
+	podSpecFieldPath = "spec.configurationSpec.template.spec"
+)
+
+// CappReference is a reference from a Capp to a ConfigMap or a Secret in its namespace.
+type CappReference struct {
+	// Kind is the kind of the referenced object, either ReferenceKindConfigMap or ReferenceKindSecret
+	Kind string
+
+	// Name is the name of the referenced object
+	Name string
+
+	// FieldPath is the path of the field of the Capp holding the reference
+	FieldPath string
+
+	// Optional is whether the reference is marked as optional, so that the Capp runs without the referenced object
+	Optional bool
+}
+
+// GetCappReferences returns the references to ConfigMaps and Secrets in the spec of the Capp, in the order
+// of the envFrom and env fields of its containers, its volumes and its image pull secrets.
+func GetCappReferences(capp cappv1alpha1.Capp) []CappReference {
+	podSpec := capp.Spec.ConfigurationSpec.Template.Spec
+	var references []CappReference
+
+	for i, container := range podSpec.Containers {
+		for j, envFrom := range container.EnvFrom {
+			fieldPath := fmt.Sprintf("%s.containers[%d].envFrom[%d]", podSpecFieldPath, i, j)
+			if envFrom.ConfigMapRef != nil {
+				references = append(references, CappReference{Kind: ReferenceKindConfigMap, Name: envFrom.ConfigMapRef.Name, FieldPath: fieldPath + ".configMapRef", Optional: ptr.Deref(envFrom.ConfigMapRef.Optional, false)})
+			}
+			if envFrom.SecretRef != nil {
+				references = append(references, CappReference{Kind: ReferenceKindSecret, Name: envFrom.SecretRef.Name, FieldPath: fieldPath + ".secretRef", Optional: ptr.Deref(envFrom.SecretRef.Optional, false)})
+			}
+		}
+	}
+
+	for i, container := range podSpec.Containers {
+		for j, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			fieldPath := fmt.Sprintf("%s.containers[%d].env[%d].valueFrom", podSpecFieldPath, i, j)
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				references = append(references, CappReference{Kind: ReferenceKindConfigMap, Name: env.ValueFrom.ConfigMapKeyRef.Name, FieldPath: fieldPath + ".configMapKeyRef", Optional: ptr.Deref(env.ValueFrom.ConfigMapKeyRef.Optional, false)})
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				references = append(references, CappReference{Kind: ReferenceKindSecret, Name: env.ValueFrom.SecretKeyRef.Name, FieldPath: fieldPath + ".secretKeyRef", Optional: ptr.Deref(env.ValueFrom.SecretKeyRef.Optional, false)})
+			}
+		}
+	}
+
+	for i, volume := range podSpec.Volumes {
+		fieldPath := fmt.Sprintf("%s.volumes[%d]", podSpecFieldPath, i)
+		if volume.ConfigMap != nil {
+			references = append(references, CappReference{Kind: ReferenceKindConfigMap, Name: volume.ConfigMap.Name, FieldPath: fieldPath + ".configMap", Optional: ptr.Deref(volume.ConfigMap.Optional, false)})
+		}
+		if volume.Secret != nil {
+			references = append(references, CappReference{Kind: ReferenceKindSecret, Name: volume.Secret.SecretName, FieldPath: fieldPath + ".secret", Optional: ptr.Deref(volume.Secret.Optional, false)})
+		}
+	}
+
+	for i, secret := range podSpec.ImagePullSecrets {
+		references = append(references, CappReference{Kind: ReferenceKindSecret, Name: secret.Name, FieldPath: fmt.Sprintf("%s.imagePullSecrets[%d]", podSpecFieldPath, i)})
+	}
+
+	return references
+}
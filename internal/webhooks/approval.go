@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"fmt"
+	"strconv"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// isApprovalUpdate checks if the update of the Capp changes its approval annotation.
+func isApprovalUpdate(capp *cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp) bool {
+	if oldCapp == nil {
+		return false
+	}
+	return capp.Annotations[utils.AnnotationKeyApprovedGeneration] != oldCapp.Annotations[utils.AnnotationKeyApprovedGeneration]
+}
+
+// mutateApproval records the username who approved the Capp, or removes it when the approval is withdrawn.
+// The last-updated-by annotation is restored from the old Capp, so that it keeps pointing at the author
+// of the changes and cannot be changed along with the approval.
+func mutateApproval(capp *cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, username string) {
+	if author, ok := oldCapp.Annotations[utils.AnnotationKeyLastUpdatedBy]; ok {
+		capp.Annotations[utils.AnnotationKeyLastUpdatedBy] = author
+	} else {
+		delete(capp.Annotations, utils.AnnotationKeyLastUpdatedBy)
+	}
+
+	if capp.Annotations[utils.AnnotationKeyApprovedGeneration] == "" {
+		delete(capp.Annotations, utils.AnnotationKeyApprovedBy)
+		return
+	}
+	capp.Annotations[utils.AnnotationKeyApprovedBy] = username
+}
+
+// validateApproval checks that an approval of the Capp references its current generation, is not combined with
+// changes to the spec of the Capp, and is not given by the user who last updated the Capp. Capps cannot be
+// approved on creation, since they are then approved by their author.
+func validateApproval(capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, username string) error {
+	approvedGeneration := capp.Annotations[utils.AnnotationKeyApprovedGeneration]
+	if approvedGeneration == "" || (oldCapp != nil && approvedGeneration == oldCapp.Annotations[utils.AnnotationKeyApprovedGeneration]) {
+		return nil
+	}
+	if oldCapp == nil {
+		return fmt.Errorf("the Capp cannot be approved on creation and must be approved by a different user than its author")
+	}
+
+	if approvedGeneration != strconv.FormatInt(oldCapp.Generation, 10) {
+		return fmt.Errorf("the %q annotation must hold the current generation %d of the Capp, got %q",
+			utils.AnnotationKeyApprovedGeneration, oldCapp.Generation, approvedGeneration)
+	}
+	if !equality.Semantic.DeepEqual(capp.Spec, oldCapp.Spec) {
+		return fmt.Errorf("the Capp must be approved separately from changes to its spec")
+	}
+	if oldCapp.Annotations[utils.AnnotationKeyLastUpdatedBy] == username {
+		return fmt.Errorf("the Capp was last updated by %q and must be approved by a different user", username)
+	}
+	return nil
+}
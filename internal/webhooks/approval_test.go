@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMutateApproval(t *testing.T) {
+	oldCapp := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{utils.AnnotationKeyLastUpdatedBy: "author"}}}
+
+	// Assert that approving records the approver and keeps the author of the changes
+	capp := oldCapp.DeepCopy()
+	capp.Annotations[utils.AnnotationKeyApprovedGeneration] = "2"
+	mutateAnnotations(capp, oldCapp, "approver")
+	assert.Equal(t, "approver", capp.Annotations[utils.AnnotationKeyApprovedBy])
+	assert.Equal(t, "author", capp.Annotations[utils.AnnotationKeyLastUpdatedBy])
+
+	// Assert that the author of the changes cannot be changed along with the approval
+	forged := oldCapp.DeepCopy()
+	forged.Annotations[utils.AnnotationKeyApprovedGeneration] = "2"
+	forged.Annotations[utils.AnnotationKeyLastUpdatedBy] = "someone-else"
+	mutateAnnotations(forged, oldCapp, "author")
+	assert.Equal(t, "author", forged.Annotations[utils.AnnotationKeyLastUpdatedBy])
+
+	// Assert that withdrawing the approval removes the approver
+	approved := capp.DeepCopy()
+	delete(capp.Annotations, utils.AnnotationKeyApprovedGeneration)
+	mutateAnnotations(capp, approved, "approver")
+	assert.NotContains(t, capp.Annotations, utils.AnnotationKeyApprovedBy)
+	assert.Equal(t, "author", capp.Annotations[utils.AnnotationKeyLastUpdatedBy])
+
+	// Assert that other updates record the user who made them
+	capp = approved.DeepCopy()
+	capp.Spec.Site = "cluster1"
+	mutateAnnotations(capp, approved, "editor")
+	assert.Equal(t, "editor", capp.Annotations[utils.AnnotationKeyLastUpdatedBy])
+	assert.Equal(t, "approver", capp.Annotations[utils.AnnotationKeyApprovedBy])
+
+	// Assert that an approval annotation given on creation is not treated as an approval
+	capp = &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{utils.AnnotationKeyApprovedGeneration: "1"}}}
+	mutateAnnotations(capp, nil, "author")
+	assert.Equal(t, "author", capp.Annotations[utils.AnnotationKeyLastUpdatedBy])
+	assert.NotContains(t, capp.Annotations, utils.AnnotationKeyApprovedBy)
+}
+
+func TestValidateApproval(t *testing.T) {
+	oldCapp := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Generation: 2, Annotations: map[string]string{utils.AnnotationKeyLastUpdatedBy: "author"}}}
+	approved := oldCapp.DeepCopy()
+	approved.Annotations[utils.AnnotationKeyApprovedGeneration] = "2"
+
+	assert.NoError(t, validateApproval(*approved, oldCapp, "approver"))
+	assert.NoError(t, validateApproval(*oldCapp, oldCapp, "author"))
+
+	// Assert that the author of the changes cannot approve them, even when claiming another author in the update
+	assert.ErrorContains(t, validateApproval(*approved, oldCapp, "author"), "must be approved by a different user")
+	forged := approved.DeepCopy()
+	forged.Annotations[utils.AnnotationKeyLastUpdatedBy] = "someone-else"
+	assert.ErrorContains(t, validateApproval(*forged, oldCapp, "author"), "must be approved by a different user")
+
+	// Assert that approvals must reference the current generation and cannot be combined with spec changes
+	invalid := approved.DeepCopy()
+	invalid.Annotations[utils.AnnotationKeyApprovedGeneration] = "latest"
+	assert.Error(t, validateApproval(*invalid, oldCapp, "approver"))
+	future := approved.DeepCopy()
+	future.Annotations[utils.AnnotationKeyApprovedGeneration] = "5"
+	assert.ErrorContains(t, validateApproval(*future, oldCapp, "approver"), "current generation 2")
+	changed := approved.DeepCopy()
+	changed.Spec.Site = "cluster1"
+	assert.ErrorContains(t, validateApproval(*changed, oldCapp, "approver"), "separately")
+
+	// Assert that a Capp cannot be approved on creation
+	assert.ErrorContains(t, validateApproval(*approved, nil, "author"), "on creation")
+}
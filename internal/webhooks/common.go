@@ -3,15 +3,14 @@ package webhooks
 import (
 	"context"
 	"fmt"
-	"net"
 	"regexp"
 	"strings"
 
 	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
 	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
-	"k8s.io/apimachinery/pkg/types"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/strings/slices"
@@ -73,30 +72,9 @@ func validateDomainName(domainName string, invalidPatterns []string) (errs *apis
 		errs = errs.Also(apis.ErrGeneric(
 			fmt.Sprintf("invalid name %q: must not be a subdomain of cluster local domain %q", domainName, clusterLocalDomain), "name"))
 	}
-	domainNameTaken, dnsErr := isDomainNameTaken(domainName)
-	if dnsErr != nil {
-		errs = errs.Also(apis.ErrGeneric(
-			fmt.Sprintf("hostname check error: %v", dnsErr.Error())))
-	}
-	if domainNameTaken {
-		errs = errs.Also(apis.ErrGeneric(
-			fmt.Sprintf("invalid name %q: hostname must be unique and not already taken", domainName), "name"))
-	}
 	return errs
 }
 
-// isDomainNameTaken checks if the given hostname is already in use.
-func isDomainNameTaken(domainName string) (bool, error) {
-	_, err := net.LookupHost(domainName)
-	if err != nil {
-		if err.(*net.DNSError).IsNotFound {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
-}
-
 // validateLogSpec checks if the LogSpec is valid based on the Type field.
 func validateLogSpec(logSpec cappv1alpha1.LogSpec) *apis.FieldError {
 	requiredFields := map[string][]string{
@@ -140,11 +118,15 @@ func findMissingFields(logSpec cappv1alpha1.LogSpec, required []string) []string
 
 // getRCSConfig returns an instance of RCS Config.
 func getRCSConfig(ctx context.Context, k8sClient client.Client) (*rcsv1alpha1.RCSConfig, error) {
-	config := rcsv1alpha1.RCSConfig{}
-	key := types.NamespacedName{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace}
-	if err := k8sClient.Get(ctx, key, &config); err != nil {
-		return nil, err
-	}
+	return utils.GetRCSConfig(ctx, k8sClient)
+}
 
-	return &config, nil
+// isSpecChange checks if the Capp is created, or updated with changes to its spec while it is not being deleted.
+// Updates of the metadata of a Capp only, such as removing its finalizers, approving or pausing it, are not
+// evaluated against the RCS Config, so that they are not blocked by changes of the RCS Config or of the namespace.
+func isSpecChange(capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp) bool {
+	if capp.DeletionTimestamp != nil {
+		return false
+	}
+	return oldCapp == nil || !equality.Semantic.DeepEqual(capp.Spec, oldCapp.Spec)
 }
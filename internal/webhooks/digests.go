@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultDigestResolutionTimeout is the timeout of resolving the digests of the images of a Capp when no timeout is configured.
+const DefaultDigestResolutionTimeout = 5 * time.Second
+
+// DigestResolver resolves the tag of an image to the digest of the image it currently points at.
+type DigestResolver interface {
+	ResolveDigest(ctx context.Context, tag name.Tag, keychain authn.Keychain) (string, error)
+}
+
+// RemoteDigestResolver is a DigestResolver which queries the registry of the image.
+type RemoteDigestResolver struct {
+	options []remote.Option
+}
+
+// NewRemoteDigestResolver returns a RemoteDigestResolver using the specified options to query registries.
+func NewRemoteDigestResolver(options ...remote.Option) *RemoteDigestResolver {
+	return &RemoteDigestResolver{options: options}
+}
+
+// ResolveDigest resolves the tag to a digest using a HEAD request to the registry, falling back to
+// fetching the manifest for registries which do not support HEAD requests.
+func (r *RemoteDigestResolver) ResolveDigest(ctx context.Context, tag name.Tag, keychain authn.Keychain) (string, error) {
+	options := append([]remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}, r.options...)
+	descriptor, err := remote.Head(tag, options...)
+	if err == nil {
+		return descriptor.Digest.String(), nil
+	}
+	manifest, err := remote.Get(tag, options...)
+	if err != nil {
+		return "", err
+	}
+	return manifest.Digest.String(), nil
+}
+
+// pullSecretKeychain is an authn.Keychain holding the credentials of image pull Secrets by registry.
+type pullSecretKeychain map[string]authn.AuthConfig
+
+// Resolve returns the credentials of the registry of the resource, or anonymous credentials if there are none.
+func (k pullSecretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	if config, ok := k[resource.RegistryStr()]; ok {
+		return authn.FromConfig(config), nil
+	}
+	return authn.Anonymous, nil
+}
+
+// add adds the credentials of the image pull Secret to the keychain. Registries which already have credentials
+// in the keychain keep them, so that the first Secret referencing a registry takes precedence.
+func (k pullSecretKeychain) add(secret corev1.Secret) error {
+	auths := map[string]authn.AuthConfig{}
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		config := struct {
+			Auths map[string]authn.AuthConfig `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return fmt.Errorf("invalid image pull Secret %q: %v", secret.Name, err.Error())
+		}
+		auths = config.Auths
+	case corev1.SecretTypeDockercfg:
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+			return fmt.Errorf("invalid image pull Secret %q: %v", secret.Name, err.Error())
+		}
+	default:
+		return nil
+	}
+
+	for server, config := range auths {
+		host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+		host, _, _ = strings.Cut(host, "/")
+		registry, err := name.NewRegistry(host)
+		if err != nil {
+			continue
+		}
+		if _, ok := k[registry.RegistryStr()]; !ok {
+			k[registry.RegistryStr()] = config
+		}
+	}
+	return nil
+}
+
+// getPullSecretKeychain returns a keychain with the credentials of the imagePullSecrets of the Capp, followed by
+// those of the pull Secrets of the RCS Config. Secrets which do not exist are skipped.
+func (c *CappMutator) getPullSecretKeychain(ctx context.Context, capp cappv1alpha1.Capp, resolution rcsv1alpha1.ImageDigestResolution) (authn.Keychain, error) {
+	secretKeys := []client.ObjectKey{}
+	for _, pullSecret := range capp.Spec.ConfigurationSpec.Template.Spec.ImagePullSecrets {
+		secretKeys = append(secretKeys, client.ObjectKey{Name: pullSecret.Name, Namespace: capp.Namespace})
+	}
+	for _, pullSecret := range resolution.PullSecrets {
+		secretKeys = append(secretKeys, client.ObjectKey{Name: pullSecret, Namespace: utils.RCSConfigNamespace})
+	}
+
+	keychain := pullSecretKeychain{}
+	for _, secretKey := range secretKeys {
+		secret := corev1.Secret{}
+		if err := c.Client.Get(ctx, secretKey, &secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get image pull Secret %q: %v", secretKey.Name, err.Error())
+		}
+		if err := keychain.add(secret); err != nil {
+			return nil, err
+		}
+	}
+	return keychain, nil
+}
+
+// getDigestResolutionTimeout returns the timeout of resolving the digests of the images of a Capp,
+// defaulting to DefaultDigestResolutionTimeout.
+func getDigestResolutionTimeout(resolution rcsv1alpha1.ImageDigestResolution) time.Duration {
+	if resolution.Timeout == nil {
+		return DefaultDigestResolutionTimeout
+	}
+	return resolution.Timeout.Duration
+}
+
+// mutateImageDigests resolves the tags of the images of the containers and init containers of the Capp to digests,
+// when digest resolution is enabled in the RCS Config, and records the original images in an annotation.
+// Images whose digest cannot be resolved are left unchanged, and are returned as warnings rather than failing the admission.
+func (c *CappMutator) mutateImageDigests(ctx context.Context, capp *cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) []string {
+	resolution := config.Spec.ImageDigestResolution
+	if resolution == nil || c.DigestResolver == nil {
+		return nil
+	}
+
+	keychain, err := c.getPullSecretKeychain(ctx, *capp, *resolution)
+	if err != nil {
+		return []string{fmt.Sprintf("image digests were not resolved: %v", err.Error())}
+	}
+	resolveCtx, cancel := context.WithTimeout(ctx, getDigestResolutionTimeout(*resolution))
+	defer cancel()
+
+	previousImages := map[string]string{}
+	if annotation, ok := capp.Annotations[utils.AnnotationKeyOriginalImages]; ok {
+		_ = json.Unmarshal([]byte(annotation), &previousImages)
+	}
+
+	var warnings []string
+	originalImages := map[string]string{}
+	podSpec := &capp.Spec.ConfigurationSpec.Template.Spec
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			container := &containers[i]
+			reference, err := name.ParseReference(container.Image)
+			if err != nil {
+				continue
+			}
+			tag, ok := reference.(name.Tag)
+			if !ok {
+				if previousImage, ok := previousImages[container.Name]; ok {
+					originalImages[container.Name] = previousImage
+				}
+				continue
+			}
+
+			digest, err := c.DigestResolver.ResolveDigest(resolveCtx, tag, keychain)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("the digest of image %q of container %q was not resolved: %v", container.Image, container.Name, err.Error()))
+				continue
+			}
+			originalImages[container.Name] = container.Image
+			container.Image = strings.TrimSuffix(container.Image, ":"+tag.TagStr()) + "@" + digest
+		}
+	}
+
+	if len(originalImages) == 0 {
+		delete(capp.Annotations, utils.AnnotationKeyOriginalImages)
+		return warnings
+	}
+	annotation, err := json.Marshal(originalImages)
+	if err != nil {
+		return append(warnings, fmt.Sprintf("failed to record the original images: %v", err.Error()))
+	}
+	if capp.Annotations == nil {
+		capp.Annotations = map[string]string{}
+	}
+	capp.Annotations[utils.AnnotationKeyOriginalImages] = string(annotation)
+	return warnings
+}
@@ -0,0 +1,134 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pushRandomImage pushes a random image to the tag on the registry, and returns its digest.
+func pushRandomImage(t *testing.T, image string) string {
+	tag, err := name.NewTag(image)
+	assert.NoError(t, err)
+	img, err := random.Image(64, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(tag, img))
+	digest, err := img.Digest()
+	assert.NoError(t, err)
+	return digest.String()
+}
+
+func TestMutateImageDigests(t *testing.T) {
+	ctx := context.TODO()
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	appDigest := pushRandomImage(t, host+"/team-a/app:1.0.0")
+	initDigest := pushRandomImage(t, host+"/team-a/init:latest")
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	mutator := CappMutator{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), DigestResolver: NewRemoteDigestResolver()}
+	config := rcsv1alpha1.RCSConfig{}
+
+	newCapp := func() *cappv1alpha1.Capp {
+		capp := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-ns"}}
+		capp.Spec.ConfigurationSpec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: host + "/team-a/init"}}
+		capp.Spec.ConfigurationSpec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: host + "/team-a/app:1.0.0"}}
+		return capp
+	}
+
+	// Assert that images are left unchanged when digest resolution is not enabled
+	capp := newCapp()
+	assert.Empty(t, mutator.mutateImageDigests(ctx, capp, config))
+	assert.Equal(t, host+"/team-a/app:1.0.0", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+
+	// Assert that tags are resolved to digests, and that the original images are recorded
+	config.Spec.ImageDigestResolution = &rcsv1alpha1.ImageDigestResolution{}
+	capp = newCapp()
+	assert.Empty(t, mutator.mutateImageDigests(ctx, capp, config))
+	assert.Equal(t, host+"/team-a/init@"+initDigest, capp.Spec.ConfigurationSpec.Template.Spec.InitContainers[0].Image)
+	assert.Equal(t, host+"/team-a/app@"+appDigest, capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+	originalImages := map[string]string{}
+	assert.NoError(t, json.Unmarshal([]byte(capp.Annotations[utils.AnnotationKeyOriginalImages]), &originalImages))
+	assert.Equal(t, map[string]string{"init": host + "/team-a/init", "app": host + "/team-a/app:1.0.0"}, originalImages)
+
+	// Assert that images pinned to digests keep their recorded original images
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image = host + "/team-a/app:1.0.0"
+	assert.Empty(t, mutator.mutateImageDigests(ctx, capp, config))
+	assert.NoError(t, json.Unmarshal([]byte(capp.Annotations[utils.AnnotationKeyOriginalImages]), &originalImages))
+	assert.Equal(t, host+"/team-a/init", originalImages["init"])
+
+	// Assert that images which cannot be resolved are left unchanged with a warning
+	capp = newCapp()
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image = host + "/team-a/missing:1.0.0"
+	warnings := mutator.mutateImageDigests(ctx, capp, config)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `container "app" was not resolved`)
+	assert.Equal(t, host+"/team-a/missing:1.0.0", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+
+	// Assert that an unreachable registry does not fail the mutation
+	server.Close()
+	capp = newCapp()
+	warnings = mutator.mutateImageDigests(ctx, capp, config)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, host+"/team-a/app:1.0.0", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, capp.Annotations, utils.AnnotationKeyOriginalImages)
+}
+
+func TestGetPullSecretKeychain(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-ns"}}
+	capp.Spec.ConfigurationSpec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "missing"}, {Name: "team-a"}}
+	teamA := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "test-ns"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+			`{"auths":{"registry.example.com":{"username":"team-a","password":"secret"}}}`)},
+	}
+	shared := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: utils.RCSConfigNamespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+			`{"auths":{"https://registry.example.com/v2/":{"username":"shared","password":"secret"},"https://index.docker.io/v1/":{"auth":"c2hhcmVkOnNlY3JldA=="}}}`)},
+	}
+	mutator := CappMutator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(teamA, shared).Build()}
+
+	// Assert that the imagePullSecrets of the Capp take precedence over the pull Secrets of the RCS Config
+	keychain, err := mutator.getPullSecretKeychain(ctx, capp, rcsv1alpha1.ImageDigestResolution{PullSecrets: []string{"shared"}})
+	assert.NoError(t, err)
+	for image, username := range map[string]string{"registry.example.com/app": "team-a", "nginx": "shared"} {
+		repository, err := name.NewRepository(image)
+		assert.NoError(t, err)
+		authenticator, err := keychain.Resolve(repository)
+		assert.NoError(t, err)
+		authConfig, err := authenticator.Authorization()
+		assert.NoError(t, err)
+		assert.Equal(t, username, authConfig.Username, image)
+	}
+
+	// Assert that registries without credentials are accessed anonymously
+	authenticator, err := keychain.Resolve(name.MustParseReference("quay.io/app").Context())
+	assert.NoError(t, err)
+	assert.Equal(t, authn.Anonymous, authenticator)
+}
@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// allNamespaces is the namespace of a domain delegation which delegates its domains to all namespaces.
+const allNamespaces = "*"
+
+// validateDomainDelegation checks that the hostname of the Capp is within one of the domains delegated
+// to its namespace, when domain delegations are configured in the RCS Config.
+func (c *CappValidator) validateDomainDelegation(ctx context.Context, capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) error {
+	hostname := capp.Spec.RouteSpec.Hostname
+	if hostname == "" || len(config.Spec.DomainDelegations) == 0 {
+		return nil
+	}
+
+	var namespaceLabels labels.Set
+	var delegatedDomains []string
+	for _, delegation := range config.Spec.DomainDelegations {
+		delegated, err := c.isDelegatedToNamespace(ctx, delegation, capp.Namespace, &namespaceLabels)
+		if err != nil {
+			return err
+		}
+		if !delegated {
+			continue
+		}
+		for _, domain := range delegation.Domains {
+			if isWithinDomain(hostname, domain) {
+				return nil
+			}
+		}
+		delegatedDomains = append(delegatedDomains, delegation.Domains...)
+	}
+
+	if len(delegatedDomains) == 0 {
+		return fmt.Errorf("invalid name %q: no domains are delegated to namespace %q", hostname, capp.Namespace)
+	}
+	return fmt.Errorf("invalid name %q: hostname must be within the domains delegated to namespace %q: %s",
+		hostname, capp.Namespace, strings.Join(delegatedDomains, ", "))
+}
+
+// isDelegatedToNamespace checks if the domain delegation applies to the namespace, either by name or by its labels.
+// The labels of the namespace are only fetched when needed, and kept for the next delegations.
+func (c *CappValidator) isDelegatedToNamespace(ctx context.Context, delegation rcsv1alpha1.DomainDelegation, namespace string, namespaceLabels *labels.Set) (bool, error) {
+	if slices.Contains(delegation.Namespaces, allNamespaces) || slices.Contains(delegation.Namespaces, namespace) {
+		return true, nil
+	}
+	if delegation.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(delegation.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector of domain delegation: %v", err.Error())
+	}
+	if *namespaceLabels == nil {
+		ns := corev1.Namespace{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+			return false, fmt.Errorf("failed to get namespace %q: %v", namespace, err.Error())
+		}
+		*namespaceLabels = labels.Set(ns.Labels)
+		if *namespaceLabels == nil {
+			*namespaceLabels = labels.Set{}
+		}
+	}
+	return selector.Matches(*namespaceLabels), nil
+}
+
+// isWithinDomain checks if the hostname is within the domain. A domain contains itself and all its subdomains,
+// while a domain starting with "*." only contains its subdomains.
+func isWithinDomain(hostname string, domain string) bool {
+	if suffix, ok := strings.CutPrefix(domain, "*"); ok {
+		return strings.HasSuffix(hostname, suffix)
+	}
+	return hostname == domain || strings.HasSuffix(hostname, "."+domain)
+}
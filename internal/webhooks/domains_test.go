@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsWithinDomain(t *testing.T) {
+	assert.True(t, isWithinDomain("team-a.example.com", "team-a.example.com"))
+	assert.True(t, isWithinDomain("app.team-a.example.com", "team-a.example.com"))
+	assert.False(t, isWithinDomain("evilteam-a.example.com", "team-a.example.com"))
+	assert.True(t, isWithinDomain("app.apps.example.com", "*.apps.example.com"))
+	assert.False(t, isWithinDomain("apps.example.com", "*.apps.example.com"))
+}
+
+func TestValidateDomainDelegation(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	teamA := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	teamB := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "b"}}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(teamA, teamB).Build()}
+	newCapp := func(namespace, hostname string) cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: namespace}}
+		capp.Spec.RouteSpec.Hostname = hostname
+		return capp
+	}
+
+	// Assert that any hostname is allowed when no domains are delegated
+	config := rcsv1alpha1.RCSConfig{}
+	assert.NoError(t, validator.validateDomainDelegation(ctx, newCapp("team-a", "app.example.org"), config))
+
+	config.Spec.DomainDelegations = []rcsv1alpha1.DomainDelegation{
+		{Domains: []string{"*.apps.example.com"}, Namespaces: []string{"*"}},
+		{Domains: []string{"team-a.example.com"}, Namespaces: []string{"team-a"}},
+		{Domains: []string{"team-b.example.com"}, NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}}},
+	}
+
+	// Assert that hostnames within the domains delegated to the namespace, by name, by label or to all namespaces, are allowed
+	assert.NoError(t, validator.validateDomainDelegation(ctx, newCapp("team-a", "app.team-a.example.com"), config))
+	assert.NoError(t, validator.validateDomainDelegation(ctx, newCapp("team-b", "app.team-b.example.com"), config))
+	assert.NoError(t, validator.validateDomainDelegation(ctx, newCapp("team-b", "app.apps.example.com"), config))
+
+	// Assert that hostnames within the domains of other tenants are denied, listing the delegated domains
+	err := validator.validateDomainDelegation(ctx, newCapp("team-a", "app.team-b.example.com"), config)
+	assert.ErrorContains(t, err, `delegated to namespace "team-a": *.apps.example.com, team-a.example.com`)
+}
+
+func TestValidatorDomainDelegationOfLiveCapp(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec: rcsv1alpha1.RCSConfigSpec{DomainDelegations: []rcsv1alpha1.DomainDelegation{
+			{Domains: []string{"team-a.example.com"}, Namespaces: []string{"team-a"}},
+		}},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, namespace).
+		WithIndex(&cappv1alpha1.Capp{}, HostnameIndexKey, CappHostnameIndexFunc).Build()
+	validator := CappValidator{Client: fakeClient}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	// The Capp was admitted before its domain was delegated to another namespace
+	oldCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "team-a", Finalizers: []string{"dana.io/capp-cleanup"}}}
+	oldCapp.Spec.RouteSpec.Hostname = "app.team-b.example.com"
+	assert.False(t, validator.handle(ctx, oldCapp, nil, user).Allowed)
+
+	// Assert that updates keeping the hostname, and Capps being deleted, are not denied
+	capp := oldCapp.DeepCopy()
+	capp.Labels = map[string]string{"team": "a"}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	capp.Finalizers = nil
+	capp.DeletionTimestamp = &metav1.Time{}
+	capp.Spec.RouteSpec.Hostname = "other.team-b.example.com"
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+
+	// Assert that changing the hostname of a live Capp is checked against the delegated domains
+	capp.DeletionTimestamp = nil
+	assert.False(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	capp.Spec.RouteSpec.Hostname = "app.team-a.example.com"
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+}
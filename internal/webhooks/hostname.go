@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HostnameIndexKey is the key of the field index of Capps by their hostname.
+	HostnameIndexKey = "spec.routeSpec.hostname"
+
+	// DefaultDNSCheckTimeout is the timeout of the DNS lookup of a hostname when no timeout is configured.
+	DefaultDNSCheckTimeout = 2 * time.Second
+
+	// DefaultDNSCacheTTL is the duration for which the result of the DNS lookup of a hostname is cached.
+	DefaultDNSCacheTTL = 5 * time.Minute
+)
+
+// HostnameChecker checks whether a hostname is already in use outside the hub.
+type HostnameChecker interface {
+	IsHostnameTaken(ctx context.Context, hostname string) (bool, error)
+}
+
+// dnsCacheEntry is the cached result of the DNS lookup of a hostname.
+type dnsCacheEntry struct {
+	taken   bool
+	expires time.Time
+}
+
+// DNSHostnameChecker is a HostnameChecker which considers a hostname taken if it resolves.
+// The results of the lookups are cached, so that repeated admissions of a Capp do not block on DNS.
+// Expired results are pruned at most once per TTL, so that the cache only holds the hostnames
+// looked up recently.
+type DNSHostnameChecker struct {
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	cacheTTL   time.Duration
+
+	mu         sync.Mutex
+	cache      map[string]dnsCacheEntry
+	lastPruned time.Time
+}
+
+// NewDNSHostnameChecker returns a DNSHostnameChecker using the specified lookup function and cache TTL.
+func NewDNSHostnameChecker(lookupHost func(ctx context.Context, host string) ([]string, error), cacheTTL time.Duration) *DNSHostnameChecker {
+	return &DNSHostnameChecker{lookupHost: lookupHost, cacheTTL: cacheTTL, cache: map[string]dnsCacheEntry{}}
+}
+
+// IsHostnameTaken checks if the hostname resolves, using the cached result when it has not expired.
+func (c *DNSHostnameChecker) IsHostnameTaken(ctx context.Context, hostname string) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[hostname]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.taken, nil
+	}
+
+	taken := true
+	if _, err := c.lookupHost(ctx, hostname); err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return false, err
+		}
+		taken = false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastPruned) >= c.cacheTTL {
+		c.pruneCache(now)
+	}
+	c.cache[hostname] = dnsCacheEntry{taken: taken, expires: now.Add(c.cacheTTL)}
+	return taken, nil
+}
+
+// pruneCache removes the expired results from the cache. It must be called with the mutex held.
+func (c *DNSHostnameChecker) pruneCache(now time.Time) {
+	for hostname, entry := range c.cache {
+		if !now.Before(entry.expires) {
+			delete(c.cache, hostname)
+		}
+	}
+	c.lastPruned = now
+}
+
+// CappHostnameIndexFunc returns the hostname of the Capp to index it by, if it has one.
+func CappHostnameIndexFunc(obj client.Object) []string {
+	capp, ok := obj.(*cappv1alpha1.Capp)
+	if !ok || capp.Spec.RouteSpec.Hostname == "" {
+		return nil
+	}
+	return []string{capp.Spec.RouteSpec.Hostname}
+}
+
+// IndexCappsByHostname registers the field index of Capps by their hostname, which is used to enforce
+// the uniqueness of hostnames across namespaces and managed clusters.
+func IndexCappsByHostname(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &cappv1alpha1.Capp{}, HostnameIndexKey, CappHostnameIndexFunc)
+}
+
+// getDNSCheckTimeout returns the timeout of the DNS lookup of a hostname configured in the RCS Config,
+// defaulting to DefaultDNSCheckTimeout.
+func getDNSCheckTimeout(config rcsv1alpha1.RCSConfig) time.Duration {
+	if config.Spec.HostnameDNSCheck == nil || config.Spec.HostnameDNSCheck.Timeout == nil {
+		return DefaultDNSCheckTimeout
+	}
+	return config.Spec.HostnameDNSCheck.Timeout.Duration
+}
+
+// isHostnameChange checks if the Capp is created, or its hostname is changed while it is not being deleted.
+// The hostname of a live Capp resolves to its own route, so it is not checked again on updates keeping it.
+func isHostnameChange(capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp) bool {
+	if capp.DeletionTimestamp != nil {
+		return false
+	}
+	return oldCapp == nil || capp.Spec.RouteSpec.Hostname != oldCapp.Spec.RouteSpec.Hostname
+}
+
+// validateHostnameUniqueness checks that the hostname of the Capp is not used by another Capp on the hub.
+// When the DNS check is enabled in the RCS Config, it also checks that the hostname does not already resolve.
+// The Capps are read from the cache of the webhook, so Capps with the same hostname which are admitted
+// concurrently, before either reaches the cache, are not detected as duplicates.
+func (c *CappValidator) validateHostnameUniqueness(ctx context.Context, capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) error {
+	hostname := capp.Spec.RouteSpec.Hostname
+	if hostname == "" {
+		return nil
+	}
+
+	capps := cappv1alpha1.CappList{}
+	if err := c.Client.List(ctx, &capps, client.MatchingFields{HostnameIndexKey: hostname}); err != nil {
+		return fmt.Errorf("hostname check error: %v", err.Error())
+	}
+	for _, owner := range capps.Items {
+		if owner.Namespace != capp.Namespace || owner.Name != capp.Name {
+			return fmt.Errorf("invalid name %q: hostname is already used by Capp %q in namespace %q", hostname, owner.Name, owner.Namespace)
+		}
+	}
+
+	if config.Spec.HostnameDNSCheck == nil || c.HostnameChecker == nil {
+		return nil
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, getDNSCheckTimeout(config))
+	defer cancel()
+	taken, err := c.HostnameChecker.IsHostnameTaken(checkCtx, hostname)
+	if err != nil {
+		return fmt.Errorf("hostname check error: %v", err.Error())
+	}
+	if taken {
+		return fmt.Errorf("invalid name %q: hostname must be unique and not already taken", hostname)
+	}
+	return nil
+}
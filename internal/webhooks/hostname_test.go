@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeLookup returns a lookup function resolving only the specified hostnames, counting its calls.
+func fakeLookup(calls *int, resolved ...string) func(ctx context.Context, host string) ([]string, error) {
+	return func(ctx context.Context, host string) ([]string, error) {
+		*calls++
+		for _, hostname := range resolved {
+			if host == hostname {
+				return []string{"192.0.2.1"}, nil
+			}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+}
+
+func TestDNSHostnameChecker(t *testing.T) {
+	ctx := context.TODO()
+	calls := 0
+	checker := NewDNSHostnameChecker(fakeLookup(&calls, "taken.example.com"), time.Hour)
+
+	taken, err := checker.IsHostnameTaken(ctx, "taken.example.com")
+	assert.NoError(t, err)
+	assert.True(t, taken)
+
+	taken, err = checker.IsHostnameTaken(ctx, "free.example.com")
+	assert.NoError(t, err)
+	assert.False(t, taken)
+
+	// Assert that the results of the lookups are cached
+	_, err = checker.IsHostnameTaken(ctx, "taken.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Assert that expired results are pruned when a new result is cached
+	checker.cache["expired.example.com"] = dnsCacheEntry{expires: time.Now().Add(-time.Minute)}
+	checker.lastPruned = time.Now().Add(-2 * time.Hour)
+	_, err = checker.IsHostnameTaken(ctx, "other.example.com")
+	assert.NoError(t, err)
+	assert.NotContains(t, checker.cache, "expired.example.com")
+	assert.Contains(t, checker.cache, "taken.example.com")
+
+	// Assert that lookup failures are reported and not cached
+	failing := NewDNSHostnameChecker(func(ctx context.Context, host string) ([]string, error) {
+		calls++
+		return nil, errors.New("i/o timeout")
+	}, time.Hour)
+	_, err = failing.IsHostnameTaken(ctx, "free.example.com")
+	assert.Error(t, err)
+	_, err = failing.IsHostnameTaken(ctx, "free.example.com")
+	assert.Error(t, err)
+	assert.Equal(t, 5, calls)
+}
+
+func TestValidateHostnameUniqueness(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+
+	existing := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "team-a"}}
+	existing.Spec.RouteSpec.Hostname = "app.example.com"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).
+		WithIndex(&cappv1alpha1.Capp{}, HostnameIndexKey, CappHostnameIndexFunc).Build()
+	calls := 0
+	validator := CappValidator{Client: fakeClient, HostnameChecker: NewDNSHostnameChecker(fakeLookup(&calls, "google.com"), time.Hour)}
+	newCapp := func(name, namespace, hostname string) cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		capp.Spec.RouteSpec.Hostname = hostname
+		return capp
+	}
+	config := rcsv1alpha1.RCSConfig{}
+
+	// Assert that a hostname used by another Capp is denied across namespaces, naming the Capp using it
+	err := validator.validateHostnameUniqueness(ctx, newCapp("other", "team-b", "app.example.com"), config)
+	assert.ErrorContains(t, err, `Capp "existing" in namespace "team-a"`)
+
+	// Assert that the Capp using the hostname can keep it, and that unused hostnames are allowed
+	assert.NoError(t, validator.validateHostnameUniqueness(ctx, newCapp("existing", "team-a", "app.example.com"), config))
+	assert.NoError(t, validator.validateHostnameUniqueness(ctx, newCapp("other", "team-b", "google.com"), config))
+	assert.Zero(t, calls)
+
+	// Assert that hostnames which resolve are denied only when the DNS check is enabled
+	config.Spec.HostnameDNSCheck = &rcsv1alpha1.HostnameDNSCheck{}
+	assert.ErrorContains(t, validator.validateHostnameUniqueness(ctx, newCapp("other", "team-b", "google.com"), config), "already taken")
+	assert.NoError(t, validator.validateHostnameUniqueness(ctx, newCapp("other", "team-b", "new.example.com"), config))
+}
+
+func TestValidatorHostnameOfLiveCapp(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cappv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec:       rcsv1alpha1.RCSConfigSpec{HostnameDNSCheck: &rcsv1alpha1.HostnameDNSCheck{}},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	oldCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "team-a", Finalizers: []string{"dana.io/capp-cleanup"}}}
+	oldCapp.Spec.RouteSpec.Hostname = "app.example.com"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, namespace, oldCapp.DeepCopy()).
+		WithIndex(&cappv1alpha1.Capp{}, HostnameIndexKey, CappHostnameIndexFunc).Build()
+	calls := 0
+	validator := CappValidator{Client: fakeClient, HostnameChecker: NewDNSHostnameChecker(fakeLookup(&calls, "app.example.com"), time.Hour)}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	// Assert that updates keeping the hostname of the Capp, which resolves to its own route, are not denied
+	capp := oldCapp.DeepCopy()
+	capp.Labels = map[string]string{"team": "a"}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	assert.Zero(t, calls)
+
+	// Assert that the hostname is not checked while the Capp is being deleted
+	capp.Finalizers = nil
+	capp.DeletionTimestamp = &metav1.Time{}
+	capp.Spec.RouteSpec.Hostname = "other.example.com"
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	assert.Zero(t, calls)
+
+	// Assert that creating another Capp with a hostname which resolves is denied
+	other := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a"}}
+	other.Spec.RouteSpec.Hostname = "app.example.com"
+	assert.False(t, validator.handle(ctx, other, nil, user).Allowed)
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/google/go-containerregistry/pkg/name"
+	"k8s.io/utils/strings/slices"
+)
+
+// validateImages checks that the images of the containers and init containers of the Capp are pulled from
+// the registries allowed by the image policy of the RCS Config, and that they do not use mutable tags if it denies them.
+// The exceptions of the policy for the namespace of the Capp are taken into account.
+func validateImages(capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) error {
+	policy := config.Spec.ImagePolicy
+	if policy == nil {
+		return nil
+	}
+
+	allowedRegistries := policy.AllowedRegistries
+	denyMutableTags := policy.DenyMutableTags
+	for _, exception := range policy.Exceptions {
+		if !slices.Contains(exception.Namespaces, capp.Namespace) {
+			continue
+		}
+		if len(allowedRegistries) > 0 {
+			allowedRegistries = append(append([]string{}, allowedRegistries...), exception.AllowedRegistries...)
+		}
+		if exception.AllowMutableTags {
+			denyMutableTags = false
+		}
+	}
+
+	podSpec := capp.Spec.ConfigurationSpec.Template.Spec
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		reference, err := name.ParseReference(container.Image)
+		if err != nil {
+			return fmt.Errorf("container %q uses an invalid image %q: %v", container.Name, container.Image, err.Error())
+		}
+		if len(allowedRegistries) > 0 && !isImageAllowed(reference, allowedRegistries) {
+			return fmt.Errorf("container %q uses image %q, which is not pulled from the allowed registries: %s",
+				container.Name, container.Image, strings.Join(allowedRegistries, ", "))
+		}
+		if tag, ok := reference.(name.Tag); ok && denyMutableTags && tag.TagStr() == name.DefaultTag {
+			return fmt.Errorf("container %q uses image %q, which must use an immutable tag or digest instead of %q",
+				container.Name, container.Image, name.DefaultTag)
+		}
+	}
+	return nil
+}
+
+// isImageAllowed checks if the image reference is pulled from one of the allowed registries or repositories.
+// An allowed repository allows itself and all the repositories nested under it.
+func isImageAllowed(reference name.Reference, allowedRegistries []string) bool {
+	repository := reference.Context().RepositoryStr()
+	for _, allowed := range allowedRegistries {
+		registryName, repositoryPrefix, _ := strings.Cut(allowed, "/")
+		registry, err := name.NewRegistry(registryName)
+		if err != nil || reference.Context().RegistryStr() != registry.RegistryStr() {
+			continue
+		}
+		if repositoryPrefix == "" || repository == repositoryPrefix || strings.HasPrefix(repository, repositoryPrefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newImagesCapp returns a Capp in the specified namespace with an init container and a container using the specified images.
+func newImagesCapp(namespace, initImage, image string) cappv1alpha1.Capp {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: namespace}}
+	capp.Spec.ConfigurationSpec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: initImage}}
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: image}}
+	return capp
+}
+
+func TestValidateImages(t *testing.T) {
+	// Assert that all images are allowed when no image policy is configured
+	config := rcsv1alpha1.RCSConfig{}
+	assert.NoError(t, validateImages(newImagesCapp("team-a", "busybox", "nginx:latest"), config))
+
+	config.Spec.ImagePolicy = &rcsv1alpha1.ImagePolicy{
+		AllowedRegistries: []string{"registry.example.com", "docker.io/library"},
+		DenyMutableTags:   true,
+		Exceptions: []rcsv1alpha1.ImagePolicyException{
+			{Namespaces: []string{"sandbox"}, AllowedRegistries: []string{"quay.io"}, AllowMutableTags: true},
+		},
+	}
+
+	// Assert that images from allowed registries and repositories with immutable tags or digests are allowed
+	assert.NoError(t, validateImages(newImagesCapp("team-a", "busybox:1.36", "registry.example.com/team-a/app:1.0.0"), config))
+	assert.NoError(t, validateImages(newImagesCapp("team-a", "docker.io/library/busybox:1.36",
+		"registry.example.com/app@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"), config))
+
+	// Assert that images from other registries and repositories are denied, including in init containers
+	err := validateImages(newImagesCapp("team-a", "quay.io/team-a/init:1.0.0", "registry.example.com/app:1.0.0"), config)
+	assert.ErrorContains(t, err, `container "init" uses image "quay.io/team-a/init:1.0.0", which is not pulled from the allowed registries`)
+	err = validateImages(newImagesCapp("team-a", "busybox:1.36", "docker.io/team-a/app:1.0.0"), config)
+	assert.ErrorContains(t, err, `container "app" uses image "docker.io/team-a/app:1.0.0"`)
+
+	// Assert that the latest tag and untagged images are denied
+	err = validateImages(newImagesCapp("team-a", "busybox", "registry.example.com/app:1.0.0"), config)
+	assert.ErrorContains(t, err, `container "init" uses image "busybox", which must use an immutable tag or digest`)
+	err = validateImages(newImagesCapp("team-a", "busybox:1.36", "registry.example.com/app:latest"), config)
+	assert.ErrorContains(t, err, `container "app" uses image "registry.example.com/app:latest"`)
+
+	// Assert that the exceptions of the namespace allow additional registries and mutable tags
+	assert.NoError(t, validateImages(newImagesCapp("sandbox", "quay.io/team-a/init", "registry.example.com/app:latest"), config))
+	assert.Error(t, validateImages(newImagesCapp("sandbox", "ghcr.io/team-a/init:1.0.0", "registry.example.com/app:1.0.0"), config))
+}
+
+func TestValidatorImagesOfUnchangedSpec(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec:       rcsv1alpha1.RCSConfigSpec{ImagePolicy: &rcsv1alpha1.ImagePolicy{AllowedRegistries: []string{"registry.example.com"}}},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, namespace).Build()}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	// The Capp was admitted before its registry was removed from the allowlist
+	oldCapp := newImagesCapp("team-a", "quay.io/team-a/init:1.0.0", "quay.io/team-a/app:1.0.0")
+	oldCapp.Finalizers = []string{"dana.io/capp-cleanup"}
+	assert.False(t, validator.handle(ctx, oldCapp, nil, user).Allowed)
+
+	// Assert that updates which do not change the spec, such as removing the finalizers, are not denied
+	capp := oldCapp.DeepCopy()
+	capp.Finalizers = nil
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+
+	// Assert that spec changes by the operator's own service accounts, such as rollbacks, are denied,
+	// while Capps being deleted are not
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Image = "quay.io/team-a/app:1.0.1"
+	assert.False(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	serviceAccount := authenticationv1.UserInfo{Username: "system:serviceaccount:" + ExcludedServiceAccountNamespace + ":controller-manager"}
+	assert.False(t, validator.handle(ctx, *capp, &oldCapp, serviceAccount).Allowed)
+	capp.DeletionTimestamp = &metav1.Time{}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+}
@@ -0,0 +1,147 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/strings/slices"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type ManifestWorkValidator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+	// ControllerUsername is the username of the service account of the operator, defaulting to DefaultControllerUsername
+	ControllerUsername string
+}
+
+// +kubebuilder:webhook:path=/validate-manifestwork,mutating=false,sideEffects=None,failurePolicy=fail,groups="work.open-cluster-management.io",resources=manifestworks,verbs=create;update;delete,versions=v1,name=manifestwork.validate.rcs.dana.io,admissionReviewVersions=v1;v1beta1
+
+const (
+	ManifestWorkValidatorServingPath = "/validate-manifestwork"
+
+	// DefaultControllerUsername is the username of the service account of the operator when deployed with kustomize
+	DefaultControllerUsername = "system:serviceaccount:" + ExcludedServiceAccountNamespace + ":rcs-deployer-controller-manager"
+
+	// ocmUsernamePrefix is the prefix of the usernames of the Open Cluster Management agents on the hub,
+	// followed by the name of the managed cluster of the agent
+	ocmUsernamePrefix = "system:open-cluster-management:"
+)
+
+// systemIdentities are the usernames of the Open Cluster Management hub controllers cleaning up the ManifestWorks
+// of removed managed clusters, and of the Kubernetes controllers deleting the namespace of a managed cluster.
+var systemIdentities = []string{
+	"system:serviceaccount:open-cluster-management-hub:cluster-manager-registration-controller-sa",
+	"system:serviceaccount:open-cluster-management-hub:cluster-manager-work-controller-sa",
+	"system:serviceaccount:kube-system:namespace-controller",
+	"system:serviceaccount:kube-system:generic-garbage-collector",
+}
+
+// Handle implements the validation webhook of ManifestWorks. ManifestWorks managed by rcs can only be
+// created, updated and deleted by the operator, by system identities, or by members of break-glass groups,
+// and their finalizers can be changed by the work agent of their cluster.
+func (m *ManifestWorkValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "manifestwork Webhook", "Name", req.Name, "Namespace", req.Namespace)
+
+	mw, oldMW := &workv1.ManifestWork{}, &workv1.ManifestWork{}
+	if req.Operation != admissionv1.Delete {
+		if err := m.Decoder.DecodeRaw(req.Object, mw); err != nil {
+			logger.Error(err, "could not decode manifestwork object")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	if req.Operation != admissionv1.Create {
+		if err := m.Decoder.DecodeRaw(req.OldObject, oldMW); err != nil {
+			logger.Error(err, "could not decode old manifestwork object")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	return m.handle(ctx, req.Operation, mw, oldMW, req.UserInfo)
+}
+
+// handle implements the main validating logic of ManifestWorks.
+func (m *ManifestWorkValidator) handle(ctx context.Context, operation admissionv1.Operation, mw, oldMW *workv1.ManifestWork, userInfo authenticationv1.UserInfo) admission.Response {
+	managedMW := getManagedManifestWork(mw, oldMW)
+	if managedMW == nil {
+		return admission.Allowed("")
+	}
+	if userInfo.Username == m.getControllerUsername() || isSystemIdentity(userInfo.Username) {
+		return admission.Allowed("")
+	}
+	if operation == admissionv1.Update && isWorkAgentOfCluster(userInfo.Username, managedMW.Namespace) && isFinalizersOnlyUpdate(mw, oldMW) {
+		return admission.Allowed("")
+	}
+
+	config, err := getRCSConfig(ctx, m.Client)
+	if err != nil && !errors.IsNotFound(err) {
+		return admission.Denied("Failed to fetch RCSConfig")
+	}
+	if config != nil {
+		for _, group := range userInfo.Groups {
+			if slices.Contains(config.Spec.BreakGlassGroups, group) {
+				return admission.Allowed("")
+			}
+		}
+	}
+
+	return admission.Denied(fmt.Sprintf("ManifestWork %q is managed by rcs for Capp %q in namespace %q and cannot be changed directly, change the Capp instead",
+		managedMW.Name, managedMW.Labels[utils.CappNameLabelKey], managedMW.Labels[utils.CappNamespaceLabelKey]))
+}
+
+// GetControllerUsername returns the username of the service account with the specified name in the specified
+// namespace, or DefaultControllerUsername if either is empty.
+func GetControllerUsername(namespace, serviceAccount string) string {
+	if namespace == "" || serviceAccount == "" {
+		return DefaultControllerUsername
+	}
+	return "system:serviceaccount:" + namespace + ":" + serviceAccount
+}
+
+// getControllerUsername returns the username of the service account of the operator.
+func (m *ManifestWorkValidator) getControllerUsername() string {
+	if m.ControllerUsername == "" {
+		return DefaultControllerUsername
+	}
+	return m.ControllerUsername
+}
+
+// getManagedManifestWork returns whichever of the new and old ManifestWork is labeled as managed by rcs,
+// so that the label can neither be added nor removed to escape the validation, or nil if none of them is.
+func getManagedManifestWork(mw, oldMW *workv1.ManifestWork) *workv1.ManifestWork {
+	for _, work := range []*workv1.ManifestWork{mw, oldMW} {
+		if work.Labels[utils.MangedByLableKey] == utils.MangedByLabelValue {
+			return work
+		}
+	}
+	return nil
+}
+
+// isSystemIdentity checks if the given username belongs to one of the system identities.
+func isSystemIdentity(username string) bool {
+	return slices.Contains(systemIdentities, username)
+}
+
+// isWorkAgentOfCluster checks if the given username belongs to an Open Cluster Management agent
+// of the managed cluster, whose ManifestWorks are in the namespace named after the cluster.
+func isWorkAgentOfCluster(username, cluster string) bool {
+	return strings.HasPrefix(username, ocmUsernamePrefix+cluster+":")
+}
+
+// isFinalizersOnlyUpdate checks if the update of the ManifestWork changes nothing but its finalizers,
+// as done by the work agent when the ManifestWork is deleted.
+func isFinalizersOnlyUpdate(mw, oldMW *workv1.ManifestWork) bool {
+	return reflect.DeepEqual(mw.Spec, oldMW.Spec) &&
+		reflect.DeepEqual(mw.Labels, oldMW.Labels) &&
+		reflect.DeepEqual(mw.Annotations, oldMW.Annotations)
+}
@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestManifestWorkValidator(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec:       rcsv1alpha1.RCSConfigSpec{BreakGlassGroups: []string{"sre"}},
+	}
+	validator := ManifestWorkValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(config).Build()}
+
+	mw := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mw",
+			Namespace: "cluster1",
+			Labels: map[string]string{
+				utils.MangedByLableKey:      utils.MangedByLabelValue,
+				utils.CappNameLabelKey:      "test-capp",
+				utils.CappNamespaceLabelKey: "test-namespace",
+			},
+		},
+	}
+	edited := mw.DeepCopy()
+	edited.Spec.Workload.Manifests = []workv1.Manifest{{}}
+	unlabeled := edited.DeepCopy()
+	unlabeled.Labels = nil
+	finalized := mw.DeepCopy()
+	finalized.Finalizers = []string{"cluster.open-cluster-management.io/manifest-work-cleanup"}
+
+	user := authenticationv1.UserInfo{Username: "user", Groups: []string{"developers"}}
+	deployer := authenticationv1.UserInfo{Username: DefaultControllerUsername}
+	otherServiceAccount := authenticationv1.UserInfo{Username: "system:serviceaccount:rcs-deployer-system:default"}
+	workAgent := authenticationv1.UserInfo{Username: "system:open-cluster-management:cluster1:agent"}
+	otherWorkAgent := authenticationv1.UserInfo{Username: "system:open-cluster-management:cluster2:agent"}
+	namespaceController := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:namespace-controller"}
+	kubeSystem := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:default"}
+	breakGlass := authenticationv1.UserInfo{Username: "sre-user", Groups: []string{"sre"}}
+
+	// Assert that users cannot change managed ManifestWorks, and that the denial points at the Capp
+	response := validator.handle(ctx, admissionv1.Update, edited, mw, user)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Result.Message, `Capp "test-capp" in namespace "test-namespace"`)
+	assert.False(t, validator.handle(ctx, admissionv1.Delete, &workv1.ManifestWork{}, mw, user).Allowed)
+	assert.False(t, validator.handle(ctx, admissionv1.Update, unlabeled, mw, user).Allowed)
+
+	// Assert that the operator, system identities and break-glass groups can change managed ManifestWorks
+	assert.True(t, validator.handle(ctx, admissionv1.Update, edited, mw, deployer).Allowed)
+	assert.True(t, validator.handle(ctx, admissionv1.Delete, &workv1.ManifestWork{}, mw, namespaceController).Allowed)
+	assert.True(t, validator.handle(ctx, admissionv1.Delete, &workv1.ManifestWork{}, mw, breakGlass).Allowed)
+
+	// Assert that other service accounts of the operator or system namespaces cannot change managed ManifestWorks
+	assert.False(t, validator.handle(ctx, admissionv1.Update, edited, mw, otherServiceAccount).Allowed)
+	assert.False(t, validator.handle(ctx, admissionv1.Delete, &workv1.ManifestWork{}, mw, kubeSystem).Allowed)
+
+	// Assert that the work agent of the cluster can only change the finalizers of its ManifestWorks
+	assert.True(t, validator.handle(ctx, admissionv1.Update, finalized, mw, workAgent).Allowed)
+	assert.True(t, validator.handle(ctx, admissionv1.Update, mw, finalized, workAgent).Allowed)
+	assert.False(t, validator.handle(ctx, admissionv1.Update, edited, mw, workAgent).Allowed)
+	assert.False(t, validator.handle(ctx, admissionv1.Update, mw, finalized, otherWorkAgent).Allowed)
+
+	// Assert that users cannot change the finalizers of managed ManifestWorks, and that unmanaged ManifestWorks are allowed
+	assert.False(t, validator.handle(ctx, admissionv1.Update, mw, finalized, user).Allowed)
+	assert.True(t, validator.handle(ctx, admissionv1.Create, unlabeled, &workv1.ManifestWork{}, user).Allowed)
+}
+
+func TestGetControllerUsername(t *testing.T) {
+	// Assert that the username is built from the namespace and service account of the pod when they are known
+	assert.Equal(t, "system:serviceaccount:rcs:rcs-ocm-deployer-controller-manager", GetControllerUsername("rcs", "rcs-ocm-deployer-controller-manager"))
+	assert.Equal(t, DefaultControllerUsername, GetControllerUsername("", ""))
+
+	validator := ManifestWorkValidator{ControllerUsername: "system:serviceaccount:rcs:manager"}
+	assert.Equal(t, "system:serviceaccount:rcs:manager", validator.getControllerUsername())
+}
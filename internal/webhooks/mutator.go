@@ -9,6 +9,7 @@ import (
 	"github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
 
 	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
@@ -19,16 +20,13 @@ import (
 )
 
 type CappMutator struct {
-	Client  client.Client
-	Decoder admission.Decoder
+	Client         client.Client
+	Decoder        admission.Decoder
+	DigestResolver DigestResolver
 }
 
 // +kubebuilder:webhook:path=/mutate-capp,mutating=true,sideEffects=NoneOnDryRun,failurePolicy=fail,groups=rcs.dana.io,resources=capps,verbs=create;update,versions=v1alpha1,name=capp.dana.io,admissionReviewVersions=v1;v1beta1
 
-var (
-	lastUpdatedByAnnotationKey = utils.RCSAPIGroup + "/last-updated-by"
-)
-
 const (
 	MutatorServingPath              = "/mutate-capp"
 	ExcludedServiceAccountNamespace = "rcs-deployer-system"
@@ -46,13 +44,26 @@ func (c *CappMutator) Handle(ctx context.Context, req admission.Request) admissi
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	var oldCapp *cappv1alpha1.Capp
+	if req.Operation == admissionv1.Update {
+		oldCapp = &cappv1alpha1.Capp{}
+		if err := c.Decoder.DecodeRaw(req.OldObject, oldCapp); err != nil {
+			logger.Error(err, "could not decode old capp object")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
 	rcsConfig, err := getRCSConfig(ctx, c.Client)
 	if err != nil {
 		logger.Error(err, "failed to get RCS Config")
-		admission.Errored(http.StatusInternalServerError, err)
+		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	c.handle(&capp, rcsConfig, req.UserInfo.Username)
+	warnings, err := c.handle(ctx, &capp, oldCapp, rcsConfig, req.UserInfo.Username)
+	if err != nil {
+		logger.Error(err, "could not mutate capp object")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
 
 	marshaledCapp, err := json.Marshal(capp)
 	if err != nil {
@@ -60,34 +71,48 @@ func (c *CappMutator) Handle(ctx context.Context, req admission.Request) admissi
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledCapp)
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledCapp).WithWarnings(warnings...)
 }
 
-// handle implements the main mutating logic. It modifies the annotations and resources of
-// a Capp based on requester data and RCS Config.
-func (c *CappMutator) handle(capp *cappv1alpha1.Capp, rcsConfig *v1alpha1.RCSConfig, username string) {
-	mutateAnnotations(capp, username)
-	mutateResources(capp, rcsConfig.Spec.DefaultResources)
+// handle implements the main mutating logic. It modifies the annotations, resources and images of
+// a Capp based on requester data and RCS Config, and returns the warnings of the mutation.
+// The resources and images of Capps being approved are left unchanged, so that the approval does not change their spec.
+func (c *CappMutator) handle(ctx context.Context, capp *cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, rcsConfig *v1alpha1.RCSConfig, username string) ([]string, error) {
+	approval := isApprovalUpdate(capp, oldCapp)
+	mutateAnnotations(capp, oldCapp, username)
+	if approval {
+		return nil, nil
+	}
+
+	profile := getResourceProfile(*rcsConfig, *capp, log.FromContext(ctx))
+	mutateResources(capp, getDefaultResources(*rcsConfig, profile))
+	return c.mutateImageDigests(ctx, capp, *rcsConfig), nil
 }
 
 // mutateAnnotations adds a last-updated-by annotation, indicating the username who last updated the Capp.
-func mutateAnnotations(capp *cappv1alpha1.Capp, username string) {
+// Updates approving the Capp record the approver instead, so that the last-updated-by annotation keeps
+// pointing at the author of the changes.
+func mutateAnnotations(capp *cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, username string) {
 	if capp.ObjectMeta.Annotations == nil {
 		capp.ObjectMeta.Annotations = make(map[string]string)
 	}
 
+	if isApprovalUpdate(capp, oldCapp) {
+		mutateApproval(capp, oldCapp, username)
+		return
+	}
+
 	if !isExcludedServiceAccount(username) {
-		capp.ObjectMeta.Annotations[lastUpdatedByAnnotationKey] = username
+		capp.ObjectMeta.Annotations[utils.AnnotationKeyLastUpdatedBy] = username
 	}
 }
 
 // mutateResources sets default values for the Capp container resources, if such do not already exist.
 func mutateResources(capp *cappv1alpha1.Capp, defaultResources corev1.ResourceRequirements) {
-	resources := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
-
-	for _, container := range capp.Spec.ConfigurationSpec.Template.Spec.Containers {
-		setResourceQuantity(&container.Resources.Requests, defaultResources.Requests, resources)
-		setResourceQuantity(&container.Resources.Limits, defaultResources.Limits, resources)
+	containers := capp.Spec.ConfigurationSpec.Template.Spec.Containers
+	for i := range containers {
+		setResourceQuantity(&containers[i].Resources.Requests, defaultResources.Requests, defaultedResourceNames)
+		setResourceQuantity(&containers[i].Resources.Limits, defaultResources.Limits, defaultedResourceNames)
 	}
 }
 
@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/pod-security-admission/api"
+	"k8s.io/pod-security-admission/policy"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podSecurityEvaluator evaluates pod specs against the levels of the Kubernetes Pod Security Standards.
+var podSecurityEvaluator = mustNewPodSecurityEvaluator()
+
+// mustNewPodSecurityEvaluator returns an evaluator of the default checks of the Pod Security Standards.
+func mustNewPodSecurityEvaluator() policy.Evaluator {
+	evaluator, err := policy.NewEvaluator(policy.DefaultChecks())
+	if err != nil {
+		panic(fmt.Sprintf("failed to create the pod security evaluator: %v", err.Error()))
+	}
+	return evaluator
+}
+
+// validatePodSecurity checks that the pod template of the Capp satisfies the Pod Security Standards level enforced
+// by the pod-security.kubernetes.io/enforce label of its namespace on the hub, and returns warnings for violations
+// of the level of the pod-security.kubernetes.io/warn label. Namespaces without these labels are privileged.
+func (c *CappValidator) validatePodSecurity(ctx context.Context, capp cappv1alpha1.Capp) ([]string, error) {
+	namespace := corev1.Namespace{}
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: capp.Namespace}, &namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %q: %v", capp.Namespace, err.Error())
+	}
+
+	privileged := api.LevelVersion{Level: api.LevelPrivileged, Version: api.LatestVersion()}
+	podSecurity, _ := api.PolicyToEvaluate(namespace.Labels, api.Policy{Enforce: privileged, Audit: privileged, Warn: privileged})
+
+	template := capp.Spec.ConfigurationSpec.Template
+	if podSecurity.Enforce.Level != api.LevelPrivileged {
+		result := policy.AggregateCheckResults(podSecurityEvaluator.EvaluatePod(podSecurity.Enforce, &template.ObjectMeta, &template.Spec.PodSpec))
+		if !result.Allowed {
+			return nil, fmt.Errorf("violates PodSecurity %q: %s", podSecurity.Enforce.String(), result.ForbiddenDetail())
+		}
+	}
+
+	if podSecurity.Warn.Level == api.LevelPrivileged || podSecurity.Warn == podSecurity.Enforce {
+		return nil, nil
+	}
+	result := policy.AggregateCheckResults(podSecurityEvaluator.EvaluatePod(podSecurity.Warn, &template.ObjectMeta, &template.Spec.PodSpec))
+	if !result.Allowed {
+		return []string{fmt.Sprintf("would violate PodSecurity %q: %s", podSecurity.Warn.String(), result.ForbiddenDetail())}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidatePodSecurity(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "privileged"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "baseline", Labels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "restricted", Labels: map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "warned", Labels: map[string]string{
+			"pod-security.kubernetes.io/enforce": "baseline", "pod-security.kubernetes.io/warn": "restricted"}}},
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, namespace := range namespaces {
+		builder = builder.WithObjects(namespace)
+	}
+	validator := CappValidator{Client: builder.Build()}
+
+	newCapp := func(namespace string, securityContext *corev1.SecurityContext, volumes ...corev1.Volume) cappv1alpha1.Capp {
+		capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: namespace}}
+		capp.Spec.ConfigurationSpec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:1.0.0", SecurityContext: securityContext}}
+		capp.Spec.ConfigurationSpec.Template.Spec.Volumes = volumes
+		return capp
+	}
+	privileged := &corev1.SecurityContext{Privileged: ptr.To(true)}
+	hostPath := corev1.Volume{Name: "host", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}}}
+	restricted := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		RunAsNonRoot:             ptr.To(true),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+
+	// Assert that any pod spec is allowed in namespaces without pod security labels
+	warnings, err := validator.validatePodSecurity(ctx, newCapp("privileged", privileged, hostPath))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// Assert that privileged containers and hostPath volumes are denied by the baseline level
+	_, err = validator.validatePodSecurity(ctx, newCapp("baseline", privileged))
+	assert.ErrorContains(t, err, `violates PodSecurity "baseline:latest": privileged`)
+	_, err = validator.validatePodSecurity(ctx, newCapp("baseline", nil, hostPath))
+	assert.ErrorContains(t, err, `hostPath volumes (volume "host")`)
+	_, err = validator.validatePodSecurity(ctx, newCapp("baseline", nil))
+	assert.NoError(t, err)
+
+	// Assert that the restricted level requires hardened security contexts
+	_, err = validator.validatePodSecurity(ctx, newCapp("restricted", nil))
+	assert.ErrorContains(t, err, `violates PodSecurity "restricted:latest"`)
+	_, err = validator.validatePodSecurity(ctx, newCapp("restricted", restricted))
+	assert.NoError(t, err)
+
+	// Assert that violations of the warned level are returned as warnings
+	warnings, err = validator.validatePodSecurity(ctx, newCapp("warned", nil))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `would violate PodSecurity "restricted:latest"`)
+}
+
+func TestValidatorPodSecurityOfUnchangedSpec(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace}}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "baseline", Labels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, namespace).Build()}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	// The Capp was admitted before the namespace enforced the baseline level
+	oldCapp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "baseline", Finalizers: []string{"dana.io/capp-cleanup"}}}
+	oldCapp.Spec.ConfigurationSpec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "app:1.0.0", SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)}}}
+	assert.False(t, validator.handle(ctx, oldCapp, nil, user).Allowed)
+
+	// Assert that updates which do not change the spec, such as pausing the Capp or removing its finalizers, are not denied
+	capp := oldCapp.DeepCopy()
+	capp.Annotations = map[string]string{utils.AnnotationKeySyncPaused: "true"}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	capp.Finalizers = nil
+	capp.DeletionTimestamp = &metav1.Time{}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+}
@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PolicyMissingReferences is the name of the rule reporting ConfigMaps and Secrets referenced by a Capp which do not exist.
+	PolicyMissingReferences = "missing-references"
+	// PolicyMissingResourceLimits is the name of the rule reporting containers without resource limits.
+	PolicyMissingResourceLimits = "missing-resource-limits"
+	// PolicyLatestImageTag is the name of the rule reporting images using the latest tag, or no tag at all.
+	PolicyLatestImageTag = "latest-image-tag"
+	// PolicyHostnameNearDeniedPattern is the name of the rule reporting hostnames which contain
+	// a match of an invalid hostname pattern, regardless of case, without matching the pattern itself.
+	PolicyHostnameNearDeniedPattern = "hostname-near-denied-pattern"
+)
+
+// policyRule is a rule of the validating webhook whose violations are denied or warned about depending on its severity.
+type policyRule struct {
+	name            string
+	defaultSeverity rcsv1alpha1.PolicySeverity
+	check           func(ctx context.Context, c *CappValidator, capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) ([]string, error)
+}
+
+// policyRules are the policy rules evaluated by the validating webhook.
+var policyRules = []policyRule{
+	{name: PolicyMissingReferences, defaultSeverity: rcsv1alpha1.PolicySeverityWarn, check: checkMissingReferences},
+	{name: PolicyMissingResourceLimits, defaultSeverity: rcsv1alpha1.PolicySeverityWarn, check: checkMissingResourceLimits},
+	{name: PolicyLatestImageTag, defaultSeverity: rcsv1alpha1.PolicySeverityWarn, check: checkLatestImageTag},
+	{name: PolicyHostnameNearDeniedPattern, defaultSeverity: rcsv1alpha1.PolicySeverityWarn, check: checkHostnameNearDeniedPattern},
+}
+
+// getPolicySeverity returns the severity of the rule configured in the RCS Config, defaulting to the default severity of the rule.
+func getPolicySeverity(config rcsv1alpha1.RCSConfig, rule policyRule) rcsv1alpha1.PolicySeverity {
+	if severity, ok := config.Spec.PolicySeverities[rule.name]; ok {
+		return severity
+	}
+	return rule.defaultSeverity
+}
+
+// evaluatePolicies evaluates the policy rules against the Capp, and returns the violations of enforced rules
+// as denials and the violations of other rules as warnings. Violations are prefixed with the name of their rule.
+func (c *CappValidator) evaluatePolicies(ctx context.Context, capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) ([]string, []string, error) {
+	var denials, warnings []string
+	for _, rule := range policyRules {
+		severity := getPolicySeverity(config, rule)
+		if severity == rcsv1alpha1.PolicySeverityDisabled {
+			continue
+		}
+		violations, err := rule.check(ctx, c, capp, config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to evaluate policy %q: %v", rule.name, err.Error())
+		}
+		for _, violation := range violations {
+			message := fmt.Sprintf("%s: %s", rule.name, violation)
+			if severity == rcsv1alpha1.PolicySeverityEnforce {
+				denials = append(denials, message)
+			} else {
+				warnings = append(warnings, message)
+			}
+		}
+	}
+	return denials, warnings, nil
+}
+
+// checkMissingReferences reports the ConfigMaps and Secrets referenced by the Capp which do not exist,
+// along with the fields of the Capp referencing them.
+func checkMissingReferences(ctx context.Context, c *CappValidator, capp cappv1alpha1.Capp, _ rcsv1alpha1.RCSConfig) ([]string, error) {
+	var missing []utils.CappReference
+	fieldPaths := map[utils.CappReference][]string{}
+	for _, reference := range utils.GetCappReferences(capp) {
+		if reference.Optional {
+			continue
+		}
+		key := utils.CappReference{Kind: reference.Kind, Name: reference.Name}
+		if paths, ok := fieldPaths[key]; ok {
+			fieldPaths[key] = append(paths, reference.FieldPath)
+			continue
+		}
+
+		exists, err := c.referenceExists(ctx, reference, capp.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			fieldPaths[key] = nil
+			continue
+		}
+		missing = append(missing, key)
+		fieldPaths[key] = []string{reference.FieldPath}
+	}
+
+	var violations []string
+	for _, reference := range missing {
+		violations = append(violations, fmt.Sprintf("%s %q does not exist, referenced by %s",
+			reference.Kind, reference.Name, strings.Join(fieldPaths[reference], ", ")))
+	}
+	return violations, nil
+}
+
+// referenceExists checks if the ConfigMap or Secret referenced by the Capp exists in its namespace.
+func (c *CappValidator) referenceExists(ctx context.Context, reference utils.CappReference, namespace string) (bool, error) {
+	var obj client.Object = &corev1.ConfigMap{}
+	if reference.Kind == utils.ReferenceKindSecret {
+		obj = &corev1.Secret{}
+	}
+	if err := c.Client.Get(ctx, client.ObjectKey{Name: reference.Name, Namespace: namespace}, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s %q: %v", reference.Kind, reference.Name, err.Error())
+	}
+	return true, nil
+}
+
+// checkMissingResourceLimits reports the containers of the Capp which have no resource limits.
+func checkMissingResourceLimits(_ context.Context, _ *CappValidator, capp cappv1alpha1.Capp, _ rcsv1alpha1.RCSConfig) ([]string, error) {
+	var violations []string
+	for _, container := range capp.Spec.ConfigurationSpec.Template.Spec.Containers {
+		if len(container.Resources.Limits) == 0 {
+			violations = append(violations, fmt.Sprintf("container %q has no resource limits", container.Name))
+		}
+	}
+	return violations, nil
+}
+
+// checkLatestImageTag reports the containers and init containers of the Capp whose image uses the latest tag,
+// or no tag at all. Images pinned to a digest are not reported.
+func checkLatestImageTag(_ context.Context, _ *CappValidator, capp cappv1alpha1.Capp, _ rcsv1alpha1.RCSConfig) ([]string, error) {
+	podSpec := capp.Spec.ConfigurationSpec.Template.Spec
+	var violations []string
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		reference, err := name.ParseReference(container.Image)
+		if err != nil {
+			continue
+		}
+		if tag, ok := reference.(name.Tag); ok && tag.TagStr() == name.DefaultTag {
+			violations = append(violations, fmt.Sprintf("container %q uses the mutable image tag %q", container.Name, name.DefaultTag))
+		}
+	}
+	return violations, nil
+}
+
+// checkHostnameNearDeniedPattern reports invalid hostname patterns which the hostname of the Capp does not match,
+// but which match a part of the hostname when the pattern is unanchored and case-insensitive.
+func checkHostnameNearDeniedPattern(_ context.Context, _ *CappValidator, capp cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig) ([]string, error) {
+	hostname := capp.Spec.RouteSpec.Hostname
+	if hostname == "" {
+		return nil, nil
+	}
+
+	var violations []string
+	for _, pattern := range config.Spec.InvalidHostnamePatterns {
+		if pattern == "" {
+			continue
+		}
+		strict, err := regexp.Compile(pattern)
+		if err != nil || strict.MatchString(hostname) {
+			continue
+		}
+		relaxed, err := regexp.Compile("(?i)" + strings.TrimSuffix(strings.TrimPrefix(pattern, "^"), "$"))
+		if err != nil {
+			continue
+		}
+		if relaxed.MatchString(hostname) {
+			violations = append(violations, fmt.Sprintf("hostname %q is close to the denied pattern %q", hostname, pattern))
+		}
+	}
+	return violations, nil
+}
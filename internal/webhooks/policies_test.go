@@ -0,0 +1,170 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newPoliciesCapp returns a Capp with the specified hostname and containers.
+func newPoliciesCapp(hostname string, containers ...corev1.Container) cappv1alpha1.Capp {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-ns"}}
+	capp.Spec.RouteSpec.Hostname = hostname
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers = containers
+	return capp
+}
+
+func TestCheckLatestImageTag(t *testing.T) {
+	capp := newPoliciesCapp("",
+		corev1.Container{Name: "tagged", Image: "registry.example.com/app:1.0.0"},
+		corev1.Container{Name: "latest", Image: "registry.example.com/app:latest"},
+		corev1.Container{Name: "untagged", Image: "app"},
+		corev1.Container{Name: "pinned", Image: "app@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	)
+	capp.Spec.ConfigurationSpec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+
+	violations, err := checkLatestImageTag(context.TODO(), nil, capp, rcsv1alpha1.RCSConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`container "init" uses the mutable image tag "latest"`,
+		`container "latest" uses the mutable image tag "latest"`,
+		`container "untagged" uses the mutable image tag "latest"`,
+	}, violations)
+}
+
+func TestCheckHostnameNearDeniedPattern(t *testing.T) {
+	config := rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{InvalidHostnamePatterns: []string{"^admin\\..*$"}}}
+
+	// Assert that hostnames matching the pattern itself, or not matching it at all, are not reported
+	violations, err := checkHostnameNearDeniedPattern(context.TODO(), nil, newPoliciesCapp("admin.example.com"), config)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+	violations, err = checkHostnameNearDeniedPattern(context.TODO(), nil, newPoliciesCapp("app.example.com"), config)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	// Assert that hostnames matching the pattern only when unanchored or regardless of case are reported
+	violations, err = checkHostnameNearDeniedPattern(context.TODO(), nil, newPoliciesCapp("my-admin.example.com"), config)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 1)
+	violations, err = checkHostnameNearDeniedPattern(context.TODO(), nil, newPoliciesCapp("Admin.example.com"), config)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 1)
+}
+
+func TestCheckMissingReferences(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-ns"}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()}
+
+	capp := newPoliciesCapp("", corev1.Container{
+		Name: "app",
+		Env: []corev1.EnvVar{
+			{Name: "USER", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "existing"}, Key: "user"}}},
+			{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}, Key: "password"}}},
+			{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "token", Optional: ptr.To(true)}}},
+		},
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "overrides"}, Optional: ptr.To(true)}},
+		},
+	})
+	capp.Spec.ConfigurationSpec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "credentials", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "credentials"}}},
+		{Name: "certificates", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certificates", Optional: ptr.To(true)}}},
+	}
+	capp.Spec.ConfigurationSpec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull-secret"}}
+
+	// Assert that each missing object is reported once, listing all the fields referencing it, and that
+	// missing objects referenced as optional are not reported
+	violations, err := checkMissingReferences(ctx, &validator, capp, rcsv1alpha1.RCSConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`Secret "credentials" does not exist, referenced by spec.configurationSpec.template.spec.containers[0].env[1].valueFrom.secretKeyRef, spec.configurationSpec.template.spec.volumes[0].secret`,
+		`Secret "pull-secret" does not exist, referenced by spec.configurationSpec.template.spec.imagePullSecrets[0]`,
+	}, violations)
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-ns"}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()}
+
+	container := corev1.Container{
+		Name:  "app",
+		Image: "registry.example.com/app:1.0.0",
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "existing"}}},
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}},
+		},
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}},
+	}
+	capp := newPoliciesCapp("", container)
+
+	// Assert that violations of rules are warned about by default, prefixed with the name of their rule
+	denials, warnings, err := validator.evaluatePolicies(ctx, capp, rcsv1alpha1.RCSConfig{})
+	assert.NoError(t, err)
+	assert.Empty(t, denials)
+	assert.Equal(t, []string{`missing-references: ConfigMap "missing" does not exist, referenced by spec.configurationSpec.template.spec.containers[0].envFrom[1].configMapRef`}, warnings)
+
+	// Assert that violations of enforced rules are denied, and that disabled rules are not evaluated
+	config := rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{PolicySeverities: map[string]rcsv1alpha1.PolicySeverity{
+		PolicyMissingReferences:     rcsv1alpha1.PolicySeverityEnforce,
+		PolicyMissingResourceLimits: rcsv1alpha1.PolicySeverityDisabled,
+	}}}
+	capp = newPoliciesCapp("", container, corev1.Container{Name: "sidecar", Image: "sidecar"})
+	denials, warnings, err = validator.evaluatePolicies(ctx, capp, config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`missing-references: ConfigMap "missing" does not exist, referenced by spec.configurationSpec.template.spec.containers[0].envFrom[1].configMapRef`}, denials)
+	assert.Equal(t, []string{`latest-image-tag: container "sidecar" uses the mutable image tag "latest"`}, warnings)
+}
+
+func TestValidatorPoliciesOfUnchangedSpec(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rcsv1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	config := &rcsv1alpha1.RCSConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RCSConfigName, Namespace: utils.RCSConfigNamespace},
+		Spec: rcsv1alpha1.RCSConfigSpec{PolicySeverities: map[string]rcsv1alpha1.PolicySeverity{
+			PolicyMissingReferences: rcsv1alpha1.PolicySeverityEnforce,
+		}},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+	validator := CappValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(config, namespace).Build()}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	// The ConfigMap referenced by the Capp was deleted after the Capp was admitted
+	oldCapp := newPoliciesCapp("", corev1.Container{
+		Name:    "app",
+		Image:   "registry.example.com/app:1.0.0",
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}}},
+	})
+	oldCapp.Finalizers = []string{"dana.io/capp-cleanup"}
+	assert.False(t, validator.handle(ctx, oldCapp, nil, user).Allowed)
+
+	// Assert that updates which do not change the spec, such as pausing the Capp or removing its finalizers, are not denied
+	capp := oldCapp.DeepCopy()
+	capp.Annotations = map[string]string{utils.AnnotationKeySyncPaused: "true"}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+	capp.Finalizers = nil
+	capp.DeletionTimestamp = &metav1.Time{}
+	assert.True(t, validator.handle(ctx, *capp, &oldCapp, user).Allowed)
+}
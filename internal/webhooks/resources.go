@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"fmt"
+	"sort"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/strings/slices"
+)
+
+// defaultedResourceNames are the resources for which default values are set on the containers of a Capp.
+var defaultedResourceNames = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+// getResourceProfile returns the first resource profile of the RCS Config matching the namespace and labels
+// of the Capp, or nil if none matches. Profiles with an invalid selector are logged and ignored, so that they
+// do not block the admission of every Capp.
+func getResourceProfile(config rcsv1alpha1.RCSConfig, capp cappv1alpha1.Capp, logger logr.Logger) *rcsv1alpha1.ResourceProfile {
+	for i, profile := range config.Spec.ResourceProfiles {
+		if len(profile.Namespaces) > 0 && !slices.Contains(profile.Namespaces, capp.Namespace) {
+			continue
+		}
+		if profile.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(profile.Selector)
+			if err != nil {
+				logger.Error(err, "Ignoring resource profile with an invalid selector", "name", profile.Name)
+				continue
+			}
+			if !selector.Matches(labels.Set(capp.Labels)) {
+				continue
+			}
+		}
+		return &config.Spec.ResourceProfiles[i]
+	}
+	return nil
+}
+
+// getDefaultResources returns the default resources of the RCS Config, overridden by those of the resource profile.
+func getDefaultResources(config rcsv1alpha1.RCSConfig, profile *rcsv1alpha1.ResourceProfile) corev1.ResourceRequirements {
+	if profile == nil {
+		return config.Spec.DefaultResources
+	}
+	return corev1.ResourceRequirements{
+		Requests: mergeResourceLists(config.Spec.DefaultResources.Requests, profile.DefaultResources.Requests),
+		Limits:   mergeResourceLists(config.Spec.DefaultResources.Limits, profile.DefaultResources.Limits),
+	}
+}
+
+// getResourceCeilings returns the resource ceilings of the RCS Config, overridden by those of the resource profile.
+func getResourceCeilings(config rcsv1alpha1.RCSConfig, profile *rcsv1alpha1.ResourceProfile) rcsv1alpha1.ResourceCeilings {
+	ceilings := rcsv1alpha1.ResourceCeilings{}
+	if config.Spec.ResourceCeilings != nil {
+		ceilings = *config.Spec.ResourceCeilings
+	}
+	if profile == nil || profile.Ceilings == nil {
+		return ceilings
+	}
+	return rcsv1alpha1.ResourceCeilings{
+		Container: mergeResourceLists(ceilings.Container, profile.Ceilings.Container),
+		Capp:      mergeResourceLists(ceilings.Capp, profile.Ceilings.Capp),
+	}
+}
+
+// mergeResourceLists returns the quantities of the base resource list, overridden by those of the override resource list.
+func mergeResourceLists(base corev1.ResourceList, override corev1.ResourceList) corev1.ResourceList {
+	merged := corev1.ResourceList{}
+	for name, quantity := range base {
+		merged[name] = quantity
+	}
+	for name, quantity := range override {
+		merged[name] = quantity
+	}
+	return merged
+}
+
+// isResourcesChange checks if the resources of the Capp may have changed, that is, if its spec changed,
+// or if its labels, which select its resource profile, changed while it is not being deleted.
+func isResourcesChange(capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp) bool {
+	if isSpecChange(capp, oldCapp) {
+		return true
+	}
+	return capp.DeletionTimestamp == nil && !equality.Semantic.DeepEqual(capp.Labels, oldCapp.Labels)
+}
+
+// validateResources checks that the requests and limits of each container of the Capp do not exceed
+// the per-container ceilings, and that their totals do not exceed the per-Capp ceilings.
+func validateResources(capp cappv1alpha1.Capp, ceilings rcsv1alpha1.ResourceCeilings) error {
+	totalRequests := corev1.ResourceList{}
+	totalLimits := corev1.ResourceList{}
+	for _, container := range capp.Spec.ConfigurationSpec.Template.Spec.Containers {
+		for _, name := range sortedResourceNames(ceilings.Container) {
+			ceiling := ceilings.Container[name]
+			if request, ok := container.Resources.Requests[name]; ok && request.Cmp(ceiling) > 0 {
+				return fmt.Errorf("container %q requests %s %s, exceeding the ceiling of %s per container", container.Name, request.String(), name, ceiling.String())
+			}
+			if limit, ok := container.Resources.Limits[name]; ok && limit.Cmp(ceiling) > 0 {
+				return fmt.Errorf("container %q is limited to %s %s, exceeding the ceiling of %s per container", container.Name, limit.String(), name, ceiling.String())
+			}
+		}
+		addResourceList(totalRequests, container.Resources.Requests)
+		addResourceList(totalLimits, container.Resources.Limits)
+	}
+
+	for _, name := range sortedResourceNames(ceilings.Capp) {
+		ceiling := ceilings.Capp[name]
+		if request, ok := totalRequests[name]; ok && request.Cmp(ceiling) > 0 {
+			return fmt.Errorf("the containers of the Capp request a total of %s %s, exceeding the ceiling of %s per Capp", request.String(), name, ceiling.String())
+		}
+		if limit, ok := totalLimits[name]; ok && limit.Cmp(ceiling) > 0 {
+			return fmt.Errorf("the containers of the Capp are limited to a total of %s %s, exceeding the ceiling of %s per Capp", limit.String(), name, ceiling.String())
+		}
+	}
+	return nil
+}
+
+// addResourceList adds the quantities of the resource list to the total resource list.
+func addResourceList(total corev1.ResourceList, resourceList corev1.ResourceList) {
+	for name, quantity := range resourceList {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// sortedResourceNames returns the names of the resources of the resource list in a stable order,
+// so that denials are reported consistently.
+func sortedResourceNames(resourceList corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(resourceList))
+	for name := range resourceList {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newResourcesCapp(namespace string, labels map[string]string, containers ...corev1.Container) cappv1alpha1.Capp {
+	capp := cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: namespace, Labels: labels}}
+	capp.Spec.ConfigurationSpec.Template.Spec.Containers = containers
+	return capp
+}
+
+func TestMutateResourcesWithProfiles(t *testing.T) {
+	config := rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{
+		DefaultResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100Mi")},
+		},
+		ResourceProfiles: []rcsv1alpha1.ResourceProfile{
+			{
+				Name: "invalid",
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "size", Operator: "Unknown"},
+				}},
+				DefaultResources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+			{
+				Name:     "large",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"size": "large"}},
+				DefaultResources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			{
+				Name:       "batch",
+				Namespaces: []string{"batch"},
+				DefaultResources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	}}
+
+	// Assert that the global defaults are applied to all the containers, without overriding existing values,
+	// while the profile with an invalid selector is ignored
+	capp := newResourcesCapp("default", nil, corev1.Container{Name: "first"}, corev1.Container{
+		Name:      "second",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}},
+	})
+	profile := getResourceProfile(config, capp, logr.Discard())
+	assert.Nil(t, profile)
+	mutateResources(&capp, getDefaultResources(config, profile))
+	containers := capp.Spec.ConfigurationSpec.Template.Spec.Containers
+	assert.Equal(t, "100m", containers[0].Resources.Requests.Cpu().String())
+	assert.Equal(t, "100Mi", containers[0].Resources.Requests.Memory().String())
+	assert.Equal(t, "200m", containers[1].Resources.Requests.Cpu().String())
+	assert.Equal(t, "100Mi", containers[1].Resources.Requests.Memory().String())
+
+	// Assert that the profile matching the labels of the Capp overrides the global defaults
+	capp = newResourcesCapp("batch", map[string]string{"size": "large"}, corev1.Container{Name: "first"})
+	profile = getResourceProfile(config, capp, logr.Discard())
+	assert.Equal(t, "large", profile.Name)
+	mutateResources(&capp, getDefaultResources(config, profile))
+	assert.Equal(t, "1", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Resources.Requests.Cpu().String())
+	assert.Equal(t, "100Mi", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Resources.Requests.Memory().String())
+
+	// Assert that the profile matching the namespace of the Capp sets ephemeral storage defaults
+	capp = newResourcesCapp("batch", nil, corev1.Container{Name: "first"})
+	profile = getResourceProfile(config, capp, logr.Discard())
+	assert.Equal(t, "batch", profile.Name)
+	mutateResources(&capp, getDefaultResources(config, profile))
+	assert.Equal(t, "1Gi", capp.Spec.ConfigurationSpec.Template.Spec.Containers[0].Resources.Requests.StorageEphemeral().String())
+}
+
+func TestValidateResources(t *testing.T) {
+	config := rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{
+		ResourceCeilings: &rcsv1alpha1.ResourceCeilings{
+			Container: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			Capp:      corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+		ResourceProfiles: []rcsv1alpha1.ResourceProfile{{
+			Name:       "large",
+			Namespaces: []string{"large"},
+			Ceilings:   &rcsv1alpha1.ResourceCeilings{Container: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+		}},
+	}}
+	container := func(name string, cpu string, memory string) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+		}}
+	}
+	validate := func(capp cappv1alpha1.Capp) error {
+		profile := getResourceProfile(config, capp, logr.Discard())
+		return validateResources(capp, getResourceCeilings(config, profile))
+	}
+
+	assert.NoError(t, validate(newResourcesCapp("default", nil, container("first", "500m", "512Mi"), container("second", "1", "512Mi"))))
+
+	// Assert that the container exceeding the per-container ceiling is named in the denial
+	err := validate(newResourcesCapp("default", nil, container("first", "500m", "256Mi"), container("second", "2", "256Mi")))
+	assert.ErrorContains(t, err, `container "second" requests 2 cpu`)
+
+	// Assert that the total of the containers is checked against the per-Capp ceiling
+	err = validate(newResourcesCapp("default", nil, container("first", "500m", "768Mi"), container("second", "500m", "768Mi")))
+	assert.ErrorContains(t, err, "total of 1536Mi memory")
+
+	// Assert that the ceilings of a matching profile override the global ceilings
+	assert.NoError(t, validate(newResourcesCapp("large", nil, container("first", "2", "512Mi"))))
+	assert.Error(t, validate(newResourcesCapp("large", nil, container("first", "2", "2Gi"))))
+}
+
+func TestIsResourcesChange(t *testing.T) {
+	oldCapp := newResourcesCapp("default", nil, corev1.Container{Name: "first"})
+
+	// Assert that creations, and changes to the spec or to the labels selecting the resource profile, are validated
+	assert.True(t, isResourcesChange(oldCapp, nil))
+	changed := newResourcesCapp("default", nil, corev1.Container{Name: "first", Image: "app:1.0.0"})
+	assert.True(t, isResourcesChange(changed, &oldCapp))
+	relabeled := newResourcesCapp("default", map[string]string{"size": "large"}, corev1.Container{Name: "first"})
+	assert.True(t, isResourcesChange(relabeled, &oldCapp))
+
+	// Assert that metadata updates and updates of deleted Capps, such as removing their finalizers, are not validated
+	annotated := oldCapp.DeepCopy()
+	annotated.Annotations = map[string]string{utils.AnnotationKeySyncPaused: "true"}
+	assert.False(t, isResourcesChange(*annotated, &oldCapp))
+	deleted := changed.DeepCopy()
+	deleted.DeletionTimestamp = &metav1.Time{}
+	assert.False(t, isResourcesChange(*deleted, &oldCapp))
+}
+
+func TestMutateApprovalKeepsResources(t *testing.T) {
+	config := &rcsv1alpha1.RCSConfig{Spec: rcsv1alpha1.RCSConfigSpec{DefaultResources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}}}
+	oldCapp := newResourcesCapp("default", nil, corev1.Container{Name: "first"})
+	oldCapp.Generation = 2
+
+	// Assert that resource defaults are not applied by approvals, which must not change the spec
+	capp := oldCapp.DeepCopy()
+	capp.Annotations = map[string]string{utils.AnnotationKeyApprovedGeneration: "2"}
+	mutator := CappMutator{}
+	_, err := mutator.handle(context.TODO(), capp, &oldCapp, config, "approver")
+	assert.NoError(t, err)
+	assert.Equal(t, oldCapp.Spec, capp.Spec)
+}
@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+const (
+	// SiteResource is the virtual resource of the rcs.dana.io API group, named after a site, which users are
+	// authorized against to deploy Capps to the site.
+	SiteResource = "sites"
+
+	// SiteVerbDeploy is the verb of the site resource required to create a Capp on the site, or to set its site.
+	SiteVerbDeploy = "deploy"
+
+	// SiteVerbMigrate is the verb of the site resource required to change the site of a placed Capp to the site.
+	SiteVerbMigrate = "migrate"
+)
+
+// validateSiteAuthorization checks, when site authorization is enabled in the RCS Config, that the user is allowed
+// to deploy the Capp to its site, or to migrate it to its new site if the Capp was already placed.
+// Capps without a site are placed by the default placements, and do not require the deploy permission.
+func (c *CappValidator) validateSiteAuthorization(ctx context.Context, capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, config rcsv1alpha1.RCSConfig, userInfo authenticationv1.UserInfo) error {
+	if !config.Spec.AuthorizeSites || isExcludedServiceAccount(userInfo.Username) {
+		return nil
+	}
+	if oldCapp != nil && capp.Spec.Site == oldCapp.Spec.Site {
+		return nil
+	}
+
+	verb := SiteVerbDeploy
+	if oldCapp != nil && utils.ContainsPlacementAnnotation(*oldCapp) {
+		verb = SiteVerbMigrate
+	} else if capp.Spec.Site == "" {
+		return nil
+	}
+
+	allowed, err := c.isSiteAllowed(ctx, capp.Namespace, capp.Spec.Site, verb, userInfo)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+	if verb == SiteVerbMigrate {
+		return fmt.Errorf("user %q is not allowed to migrate Capps to site %q, which is required to change the site of a placed Capp", userInfo.Username, capp.Spec.Site)
+	}
+	return fmt.Errorf("user %q is not allowed to deploy Capps to site %q", userInfo.Username, capp.Spec.Site)
+}
+
+// isSiteAllowed checks using a SubjectAccessReview if the user is allowed the verb on the site resource
+// in the namespace, so that both ClusterRoles and Roles of the namespace can grant it.
+func (c *CappValidator) isSiteAllowed(ctx context.Context, namespace, site, verb string, userInfo authenticationv1.UserInfo) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range userInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	review := authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     utils.RCSAPIGroup,
+				Resource:  SiteResource,
+				Name:      site,
+			},
+		},
+	}
+	if err := c.Client.Create(ctx, &review); err != nil {
+		return false, fmt.Errorf("failed to review the access to site %q: %v", site, err.Error())
+	}
+	return review.Status.Allowed, nil
+}
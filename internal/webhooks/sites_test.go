@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	rcsv1alpha1 "github.com/dana-team/rcs-ocm-deployer/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/utils"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestValidateSiteAuthorization(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, authorizationv1.AddToScheme(scheme))
+
+	// The authorizer allows the developer to deploy to cluster1, and the operator to deploy and migrate to any site
+	grants := map[string][]string{
+		"developer": {SiteVerbDeploy + "/cluster1"},
+		"operator":  {SiteVerbDeploy + "/", SiteVerbMigrate + "/"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			review := obj.(*authorizationv1.SubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			assert.Equal(t, utils.RCSAPIGroup, attributes.Group)
+			assert.Equal(t, SiteResource, attributes.Resource)
+			for _, grant := range grants[review.Spec.User] {
+				if grant == attributes.Verb+"/"+attributes.Name || grant == attributes.Verb+"/" {
+					review.Status.Allowed = true
+				}
+			}
+			return nil
+		},
+	}).Build()
+	validator := CappValidator{Client: fakeClient}
+
+	newCapp := func(site string, placed bool) *cappv1alpha1.Capp {
+		capp := &cappv1alpha1.Capp{ObjectMeta: metav1.ObjectMeta{Name: "test-capp", Namespace: "test-ns"}}
+		capp.Spec.Site = site
+		if placed {
+			capp.Annotations = map[string]string{utils.AnnotationKeyHasPlacement: site}
+		}
+		return capp
+	}
+	developer := authenticationv1.UserInfo{Username: "developer"}
+	operator := authenticationv1.UserInfo{Username: "operator"}
+
+	// Assert that any site is allowed when site authorization is not enabled
+	config := rcsv1alpha1.RCSConfig{}
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("cluster2", false), nil, config, developer))
+
+	// Assert that users must be allowed to deploy to the site of the Capps they create
+	config.Spec.AuthorizeSites = true
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("cluster1", false), nil, config, developer))
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("", false), nil, config, developer))
+	err := validator.validateSiteAuthorization(ctx, *newCapp("cluster2", false), nil, config, developer)
+	assert.ErrorContains(t, err, `user "developer" is not allowed to deploy Capps to site "cluster2"`)
+
+	// Assert that updates which keep the site are not reviewed
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("cluster2", true), newCapp("cluster2", true), config, developer))
+
+	// Assert that the site of a placed Capp can only be changed by users allowed to migrate to the new site
+	err = validator.validateSiteAuthorization(ctx, *newCapp("cluster1", false), newCapp("cluster2", true), config, developer)
+	assert.ErrorContains(t, err, `user "developer" is not allowed to migrate Capps to site "cluster1"`)
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("cluster1", false), newCapp("cluster2", true), config, operator))
+
+	// Assert that the operator's own service accounts are not reviewed
+	serviceAccount := authenticationv1.UserInfo{Username: "system:serviceaccount:" + ExcludedServiceAccountNamespace + ":controller-manager"}
+	assert.NoError(t, validator.validateSiteAuthorization(ctx, *newCapp("cluster2", false), newCapp("cluster1", true), config, serviceAccount))
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	"github.com/go-logr/logr"
@@ -15,11 +17,14 @@ import (
 )
 
 type CappValidator struct {
-	Client  client.Client
-	Decoder admission.Decoder
-	Log     logr.Logger
+	Client          client.Client
+	Decoder         admission.Decoder
+	Log             logr.Logger
+	HostnameChecker HostnameChecker
 }
 
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
 // +kubebuilder:webhook:path=/validate-capp,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="rcs.dana.io",resources=capps,verbs=create;update,versions=v1alpha1,name=capp.validate.rcs.dana.io,admissionReviewVersions=v1;v1beta1
 
 const ValidatorServingPath = "/validate-capp"
@@ -34,8 +39,9 @@ func (c *CappValidator) Handle(ctx context.Context, req admission.Request) admis
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	oldCapp := &cappv1alpha1.Capp{}
+	var oldCapp *cappv1alpha1.Capp
 	if req.Operation == admissionv1.Update {
+		oldCapp = &cappv1alpha1.Capp{}
 		err := c.Decoder.DecodeRaw(req.OldObject, oldCapp)
 		if err != nil {
 			logger.Error(err, "could not decode old capp object")
@@ -43,10 +49,10 @@ func (c *CappValidator) Handle(ctx context.Context, req admission.Request) admis
 		}
 	}
 
-	return c.handle(ctx, capp, oldCapp)
+	return c.handle(ctx, capp, oldCapp, req.UserInfo)
 }
 
-func (c *CappValidator) handle(ctx context.Context, capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp) admission.Response {
+func (c *CappValidator) handle(ctx context.Context, capp cappv1alpha1.Capp, oldCapp *cappv1alpha1.Capp, userInfo authenticationv1.UserInfo) admission.Response {
 	config, err := getRCSConfig(ctx, c.Client)
 	if err != nil {
 		return admission.Denied("Failed to fetch RCSConfig")
@@ -56,16 +62,25 @@ func (c *CappValidator) handle(ctx context.Context, capp cappv1alpha1.Capp, oldC
 	if !isSiteValid(capp, placements, c.Client, ctx) {
 		return admission.Denied(fmt.Sprintf("this site %s is unsupported. Site field accepts either cluster name or placement name", capp.Spec.Site))
 	}
+	if err := c.validateSiteAuthorization(ctx, capp, oldCapp, *config, userInfo); err != nil {
+		return admission.Denied(err.Error())
+	}
 
 	var invalidHostnamePatterns []string
 	if config.Spec.InvalidHostnamePatterns != nil {
 		invalidHostnamePatterns = config.Spec.InvalidHostnamePatterns
 	}
 
-	if oldCapp == nil || capp.Spec.RouteSpec.Hostname != oldCapp.Spec.RouteSpec.Hostname {
+	if isHostnameChange(capp, oldCapp) {
 		if errs := validateDomainName(capp.Spec.RouteSpec.Hostname, invalidHostnamePatterns); errs != nil {
 			return admission.Denied(errs.Error())
 		}
+		if err := c.validateDomainDelegation(ctx, capp, *config); err != nil {
+			return admission.Denied(err.Error())
+		}
+		if err := c.validateHostnameUniqueness(ctx, capp, *config); err != nil {
+			return admission.Denied(err.Error())
+		}
 	}
 
 	if capp.Spec.LogSpec != (cappv1alpha1.LogSpec{}) {
@@ -74,5 +89,39 @@ func (c *CappValidator) handle(ctx context.Context, capp cappv1alpha1.Capp, oldC
 		}
 	}
 
-	return admission.Allowed("")
+	if isResourcesChange(capp, oldCapp) {
+		profile := getResourceProfile(*config, capp, log.FromContext(ctx))
+		if err := validateResources(capp, getResourceCeilings(*config, profile)); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+
+	if isSpecChange(capp, oldCapp) {
+		if err := validateImages(capp, *config); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+
+	if err := validateApproval(capp, oldCapp, userInfo.Username); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if !isSpecChange(capp, oldCapp) {
+		return admission.Allowed("")
+	}
+
+	podSecurityWarnings, err := c.validatePodSecurity(ctx, capp)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	denials, warnings, err := c.evaluatePolicies(ctx, capp, *config)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	warnings = append(podSecurityWarnings, warnings...)
+	if len(denials) > 0 {
+		return admission.Denied(strings.Join(denials, "; ")).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
+	"github.com/dana-team/rcs-ocm-deployer/internal/sync/adapters"
 	"github.com/dana-team/rcs-ocm-deployer/test/e2e_tests/testconsts"
 
 	mock "github.com/dana-team/rcs-ocm-deployer/test/e2e_tests/mocks"
@@ -16,8 +17,7 @@ import (
 )
 
 const (
-	namespaceManifestWorkPrefix = "mw-create-"
-	envVarName                  = "E2E-TEST"
+	envVarName = "E2E-TEST"
 )
 
 // verifySecretOrConfigMapCopy makes sure a Secret or a ConfigMap is eventually copied to the ManifestWork,
@@ -36,7 +36,7 @@ func verifySecretOrConfigMapCopy(baseCapp *cappv1alpha1.Capp, name, namespace, k
 	}
 
 	Eventually(func() bool {
-		mwName := namespaceManifestWorkPrefix + assertionCapp.Namespace + "-" + assertionCapp.Name
+		mwName := adapters.GenerateMWName(*assertionCapp)
 		_ = k8sClient.Get(context.Background(), client.ObjectKey{Name: mwName, Namespace: mwNamespace}, manifestWork)
 		object, err := utilst.IsObjInManifestWork(k8sClient, *manifestWork, name, namespace, resourceFactory[kind], kind)
 		Expect(err).Should(BeNil())
@@ -73,7 +73,7 @@ var _ = Describe("Validate the placement sync controller", func() {
 		By("Checks if ManifestWork was deleted")
 		manifestWork := &workv1.ManifestWork{}
 		Eventually(func() bool {
-			mwName := namespaceManifestWorkPrefix + assertionCapp.Namespace + "-" + assertionCapp.Name
+			mwName := adapters.GenerateMWName(*assertionCapp)
 			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: mwName, Namespace: mwNamespace}, manifestWork)).Should(Succeed())
 			return utilst.DoesResourceExist(k8sClient, manifestWork)
 		}, testconsts.Timeout, testconsts.Interval).ShouldNot(BeFalse())
@@ -235,7 +235,7 @@ var _ = Describe("Validate the placement sync controller", func() {
 		manifestWork := &workv1.ManifestWork{}
 
 		Eventually(func() bool {
-			mwName := namespaceManifestWorkPrefix + assertionCapp.Namespace + "-" + assertionCapp.Name
+			mwName := adapters.GenerateMWName(*assertionCapp)
 			_ = k8sClient.Get(context.Background(), client.ObjectKey{Name: mwName, Namespace: mwNamespace}, manifestWork)
 			return utilst.IsRbacObjInManifestWork(*manifestWork, assertionCapp.Name, role.Namespace, "Role") &&
 				utilst.IsRbacObjInManifestWork(*manifestWork, assertionCapp.Name, roleBinding.Namespace, "RoleBinding")
@@ -253,7 +253,7 @@ var _ = Describe("Validate the placement sync controller", func() {
 		manifestWork := &workv1.ManifestWork{}
 
 		Eventually(func() bool {
-			mwName := namespaceManifestWorkPrefix + assertionCapp.Namespace + "-" + assertionCapp.Name
+			mwName := adapters.GenerateMWName(*assertionCapp)
 			_ = k8sClient.Get(context.Background(), client.ObjectKey{Name: mwName, Namespace: mwNamespace}, manifestWork)
 			ns, err := utilst.IsObjInManifestWork(k8sClient, *manifestWork, assertionCapp.Namespace, "", &corev1.Namespace{}, "Namespace")
 			Expect(err).Should(BeNil())
@@ -276,7 +276,7 @@ var _ = Describe("Validate the placement sync controller", func() {
 
 		By("Checks Capp site is not nil")
 		manifestWork := &workv1.ManifestWork{}
-		mwName := namespaceManifestWorkPrefix + assertionCapp.Namespace + "-" + assertionCapp.Name
+		mwName := adapters.GenerateMWName(*assertionCapp)
 		Eventually(func() interface{} {
 			_ = k8sClient.Get(context.Background(), client.ObjectKey{Name: mwName, Namespace: mwNamespace}, manifestWork)
 			return utilst.GetCappFromManifestWork(*manifestWork).Object["spec"].(map[string]interface{})["site"]
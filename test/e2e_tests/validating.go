@@ -5,6 +5,7 @@ import (
 
 	cappv1alpha1 "github.com/dana-team/container-app-operator/api/v1alpha1"
 	mock "github.com/dana-team/rcs-ocm-deployer/test/e2e_tests/mocks"
+	"github.com/dana-team/rcs-ocm-deployer/test/e2e_tests/testconsts"
 	utilst "github.com/dana-team/rcs-ocm-deployer/test/e2e_tests/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -58,6 +59,21 @@ var _ = Describe("Validate the validating webhook", func() {
 		Expect(k8sClient.Create(context.Background(), baseCapp)).ShouldNot(Succeed())
 	})
 
+	It("Should deny the use of a hostname used by another Capp", func() {
+		baseCapp := mock.CreateBaseCapp()
+		baseCapp.Name = utilst.GenerateUniqueCappName(baseCapp.Name)
+		validHostName := baseCapp.Name + validDomainSuffix
+		baseCapp.Spec.RouteSpec.Hostname = validHostName
+		Expect(k8sClient.Create(context.Background(), baseCapp)).Should(Succeed())
+
+		Eventually(func() error {
+			duplicateCapp := mock.CreateBaseCapp()
+			duplicateCapp.Name = utilst.GenerateUniqueCappName(duplicateCapp.Name)
+			duplicateCapp.Spec.RouteSpec.Hostname = validHostName
+			return k8sClient.Create(context.Background(), duplicateCapp)
+		}, testconsts.Timeout, testconsts.Interval).ShouldNot(Succeed())
+	})
+
 	It("Should deny the use of a hostname in cluster local", func() {
 		baseCapp := mock.CreateBaseCapp()
 		baseCapp.Name = utilst.GenerateUniqueCappName(baseCapp.Name)